@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// auditLogger is the logger returned by AuditLogger, built by Init.
+var auditLogger *CLogger
+
+// auditCore holds the current core of auditLogger, which Reconfigure replaces
+// along with the core of the global logger.
+var auditCore *atomic.Pointer[zapcore.Core]
+
+// AuditLogger returns the audit logger, for security and compliance events
+// which must never be lost. Unlike the global logger, it logs at Info level
+// whatever the configured level, and never samples, rate limits nor buffers
+// its entries. It writes to AuditOutputPaths, or to the regular outputs, with
+// the same encoding and fields as the global logger, under the "audit" name:
+//
+//	logger.AuditLogger().Info("User role changed", zap.String("user_id", id), zap.String("role", role))
+//
+// Without AuditOutputPaths, it shares the outputs of the global logger rather
+// than opening them a second time, RotatingFile included. Reconfigure rebuilds
+// it along with the global logger. You must have initialized the logger prior
+// to this call.
+func AuditLogger() *CLogger {
+	if auditLogger == nil {
+		panic("logger not initialized. Call Init(ctx)")
+	}
+	return auditLogger
+}
+
+// audit returns the options of the audit logger: those of the global logger
+// writing to the audit outputs only, at Info level, without the settings
+// which may drop entries.
+func (o Options) audit() Options {
+	level := zap.InfoLevel
+	o.Level = &level
+	o.LevelEnv = "-"
+	o.DisableSampling = true
+	o.RateLimit = nil
+	o.Buffering = nil
+	if len(o.AuditOutputPaths) > 0 {
+		o.OutputPaths = o.AuditOutputPaths
+	}
+	// the other outputs are the global logger's only
+	o.RotatingFile = nil
+	o.Syslog = nil
+	o.HighSeverityOutputPaths = nil
+	o.HTTPSink = nil
+	return o
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAuditLoggerSharesOutputs(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	out := newMemorySink(t, "audit-shared")
+	errOut := newMemorySink(t, "audit-shared-errors")
+	if err := InitWithOptionsE(context.Background(), Options{
+		Quiet:            true,
+		OutputPaths:      []string{"memory://audit-shared"},
+		ErrorOutputPaths: []string{"memory://audit-shared-errors"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if out.openCount() != 1 || errOut.openCount() != 1 {
+		t.Errorf("outputs opened %d and %d times, want once", out.openCount(), errOut.openCount())
+	}
+	AuditLogger().Info("Role changed")
+	if got := out.String(); !strings.Contains(got, `"logger":"audit"`) {
+		t.Errorf("output = %q, want the audit entry", got)
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Buffering configures the buffering of the writes to the output paths, which
+// trades a little latency for a much higher throughput under load. Entries
+// still in the buffer are written when it is full, every FlushInterval and on
+// Sync, so call Sync before exiting to not lose them.
+type Buffering struct {
+	// Size is the size of the buffer in bytes. Defaults to 256 kB.
+	Size int
+	// FlushInterval is how often the buffer is flushed. Defaults to 30
+	// seconds.
+	FlushInterval time.Duration
+	// FlushLevel, when set, flushes the buffer as soon as an entry at or above
+	// this level is written, e.g. Error, so that the entries leading to it are
+	// not lost if the process then crashes.
+	FlushLevel *zapcore.Level
+}
+
+// flushOnLevelCore is a zapcore.Core which syncs the wrapped core after
+// writing an entry at or above level.
+type flushOnLevelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c flushOnLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return flushOnLevelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c flushOnLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c flushOnLevelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if err := c.Core.Write(ent, fields); err != nil {
+		return err
+	}
+	if ent.Level >= c.level {
+		return c.Core.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// loggerContextKey is the context key NewContext stores loggers under.
+type loggerContextKey struct{}
+
+// fieldsContextKey is the context key AppendFields stores fields under.
+type fieldsContextKey struct{}
+
+// NewContext returns a child context carrying l, to be retrieved with
+// FromContext deep down the call stack instead of passing the logger around.
+func NewContext(ctx context.Context, l *CLogger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger stored in the context by NewContext, or the
+// global logger if there is none, with the fields added to the context by
+// AppendFields.
+func FromContext(ctx context.Context) *CLogger {
+	l, ok := ctx.Value(loggerContextKey{}).(*CLogger)
+	if !ok || l == nil {
+		l = Logger()
+	}
+	if fields, ok := ctx.Value(fieldsContextKey{}).([]zap.Field); ok {
+		l = l.With(fields...)
+	}
+	return l
+}
+
+// AppendFields returns a child context carrying fields on top of the ones
+// already added to ctx, which FromContext then adds to the logger. It lets
+// each layer of a request enrich the logs, e.g. an auth middleware adding the
+// user ID and a handler the order ID:
+//
+//	ctx = logger.AppendFields(ctx, zap.String("user_id", user.ID))
+//	...
+//	logger.FromContext(ctx).Info("Order placed")
+func AppendFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	current, _ := ctx.Value(fieldsContextKey{}).([]zap.Field)
+	// the full slice expression keeps sibling contexts from sharing an array
+	return context.WithValue(ctx, fieldsContextKey{}, append(current[:len(current):len(current)], fields...))
+}
+
+// DetachedContext returns a context carrying the correlation ID, the values of
+// the context keys registered with RegisterContextField, the logger and the
+// fields of ctx, but none of its other values nor its cancellation and
+// deadline. Use it for background work spawned by a request which must outlive
+// it but remain traceable:
+//
+//	go sendReceipt(logger.DetachedContext(r.Context()), order)
+func DetachedContext(ctx context.Context) context.Context {
+	detached := context.Background()
+	if correlationId := ctx.Value(correlationIdContextKey{}); correlationId != nil {
+		detached = context.WithValue(detached, correlationIdContextKey{}, correlationId)
+	}
+	contextKey := globalCorrelationKeys.Load().contextKey
+	if correlationId := ctx.Value(contextKey); correlationId != nil {
+		detached = context.WithValue(detached, contextKey, correlationId)
+	}
+	for _, k := range registeredContextKeys() {
+		if v := ctx.Value(k); v != nil {
+			detached = context.WithValue(detached, k, v)
+		}
+	}
+	if l := ctx.Value(loggerContextKey{}); l != nil {
+		detached = context.WithValue(detached, loggerContextKey{}, l)
+	}
+	if fields := ctx.Value(fieldsContextKey{}); fields != nil {
+		detached = context.WithValue(detached, fieldsContextKey{}, fields)
+	}
+	return detached
+}
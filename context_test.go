@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDetachedContext(t *testing.T) {
+	RegisterContextField("tenant_id", "tenant")
+	t.Cleanup(func() {
+		contextFieldsMu.Lock()
+		contextFields = nil
+		contextFieldsMu.Unlock()
+	})
+	out := newMemorySink(t, "detached")
+	l, err := New(Options{OutputPaths: []string{"memory://detached"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(ContextWithCorrelationId(context.Background(), "42"), "tenant_id", "acme"))
+	cancel()
+	detached := DetachedContext(ctx)
+	if detached.Err() != nil {
+		t.Errorf("detached context error = %v, want nil", detached.Err())
+	}
+	l.WithContextCorrelationId(detached).WithContextFields(detached).Info("Receipt sent")
+	got := out.String()
+	for _, want := range []string{`"correlation_id":"42"`, `"tenant":"acme"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// contextField maps a context key to the field key its value is logged under.
+type contextField struct {
+	ctxKey   string
+	fieldKey string
+}
+
+var (
+	contextFieldsMu sync.RWMutex
+	contextFields   []contextField
+)
+
+// RegisterContextField registers a context key, e.g. "tenant_id", whose value
+// WithContextFields logs under fieldKey. Call it at startup. Registering the
+// same context key again changes its field key.
+func RegisterContextField(ctxKey, fieldKey string) {
+	if ctxKey == "" || fieldKey == "" {
+		return
+	}
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+	for i := range contextFields {
+		if contextFields[i].ctxKey == ctxKey {
+			contextFields[i].fieldKey = fieldKey
+			return
+		}
+	}
+	contextFields = append(contextFields, contextField{ctxKey: ctxKey, fieldKey: fieldKey})
+}
+
+// registeredContextKeys returns the context keys registered with
+// RegisterContextField.
+func registeredContextKeys() []string {
+	contextFieldsMu.RLock()
+	defer contextFieldsMu.RUnlock()
+	keys := make([]string, len(contextFields))
+	for i, f := range contextFields {
+		keys[i] = f.ctxKey
+	}
+	return keys
+}
+
+// WithContextFields returns an instance of the same logger with the values of
+// the given context keys added to it, under the field keys registered with
+// RegisterContextField. Keys which were not registered are logged under their
+// own name. Without keys, all the registered context keys are used. Missing
+// values are skipped; values are converted like correlation IDs.
+func (l *CLogger) WithContextFields(ctx context.Context, keys ...string) *CLogger {
+	fields := contextFieldValues(ctx, keys)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// WithContextFields returns an instance of the same logger with the values of
+// the given context keys added to it, under the field keys registered with
+// RegisterContextField. Keys which were not registered are logged under their
+// own name. Without keys, all the registered context keys are used. Missing
+// values are skipped; values are converted like correlation IDs.
+func (l *CSugaredLogger) WithContextFields(ctx context.Context, keys ...string) *CSugaredLogger {
+	fields := contextFieldValues(ctx, keys)
+	if len(fields) == 0 {
+		return l
+	}
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.Desugar().With(fields...).Sugar(), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// contextFieldValues returns the fields for the given context keys, or for all
+// the registered ones if keys is empty.
+func contextFieldValues(ctx context.Context, keys []string) []zap.Field {
+	contextFieldsMu.RLock()
+	defer contextFieldsMu.RUnlock()
+
+	mapping := contextFields
+	if len(keys) > 0 {
+		mapping = make([]contextField, 0, len(keys))
+		for _, k := range keys {
+			mapping = append(mapping, contextField{ctxKey: k, fieldKey: contextFieldKey(k)})
+		}
+	}
+	var fields []zap.Field
+	for _, m := range mapping {
+		if v, ok := correlationIdString(ctx.Value(m.ctxKey)); ok {
+			fields = append(fields, zap.String(m.fieldKey, v))
+		}
+	}
+	return fields
+}
+
+// contextFieldKey returns the field key registered for ctxKey, or ctxKey
+// itself. The caller must hold contextFieldsMu.
+func contextFieldKey(ctxKey string) string {
+	for _, m := range contextFields {
+		if m.ctxKey == ctxKey {
+			return m.fieldKey
+		}
+	}
+	return ctxKey
+}
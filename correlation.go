@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// correlationIdKeys are the keys of the correlation ID in contexts and in log
+// entries. contextKey always holds a string, boxed once so that looking it up
+// does not allocate.
+type correlationIdKeys struct {
+	contextKey interface{}
+	fieldKey   string
+}
+
+// globalCorrelationKeys holds the keys of the global logger, which the
+// package-level functions, the middlewares and the interceptors use too. They
+// are replaced rather than modified, so that the loggers derived from the
+// global one keep the keys in use when they were derived.
+var globalCorrelationKeys atomic.Pointer[correlationIdKeys]
+
+func init() {
+	globalCorrelationKeys.Store(&correlationIdKeys{contextKey: "correlation_id", fieldKey: "correlation_id"})
+}
+
+// keys returns the correlation ID keys of the logger, or the global ones for
+// the global logger and loggers which were not built by this package. The
+// global logger does not freeze its keys: SetCorrelationIdFieldKey and
+// SetCorrelationIdContextKey are how it is configured, after Init too. Code
+// which must keep reading the correlation IDs stored under the previous
+// context key derives its logger before the change.
+func (l *CLogger) keys() *correlationIdKeys {
+	if l.correlationKeys == nil {
+		return globalCorrelationKeys.Load()
+	}
+	return l.correlationKeys
+}
+
+// keys returns the correlation ID keys of the logger, or the global ones for
+// the global logger and loggers which were not built by this package. See
+// CLogger.keys for why the global logger does not freeze them.
+func (l *CSugaredLogger) keys() *correlationIdKeys {
+	if l.correlationKeys == nil {
+		return globalCorrelationKeys.Load()
+	}
+	return l.correlationKeys
+}
+
+// correlationIdContextKey is the key under which this package stores
+// correlation IDs in contexts. Being unexported, it cannot collide with keys of
+// other packages.
+type correlationIdContextKey struct{}
+
+// ContextWithCorrelationId returns a child context of ctx carrying
+// correlationId, which WithContextCorrelationId and CorrelationIdFromContext
+// then pick up.
+func ContextWithCorrelationId(ctx context.Context, correlationId string) context.Context {
+	return context.WithValue(ctx, correlationIdContextKey{}, correlationId)
+}
+
+// contextCorrelationId returns the correlation ID carried by ctx, looking it up
+// under the key of this package first and then under contextKey, for contexts
+// filled by the application itself.
+func contextCorrelationId(ctx context.Context, contextKey interface{}) interface{} {
+	if correlationId := ctx.Value(correlationIdContextKey{}); correlationId != nil {
+		return correlationId
+	}
+	return ctx.Value(contextKey)
+}
+
+// CorrelationIdFromContext returns the correlation ID stored in the context
+// under the correlation ID context key, and whether one was found. Use it to
+// propagate the ID, e.g. in HTTP response headers or RPC metadata. Non-string
+// IDs are converted the same way WithCorrelationId does.
+func CorrelationIdFromContext(ctx context.Context) (string, bool) {
+	return correlationIdString(contextCorrelationId(ctx, globalCorrelationKeys.Load().contextKey))
+}
+
+// correlationIdString converts a correlation ID of any type to a string. It
+// returns false only for nil.
+func correlationIdString(correlationId interface{}) (string, bool) {
+	switch v := correlationId.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case fmt.Stringer:
+		return v.String(), true
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
+
+// WithOrNewContextCorrelationId is like WithContextCorrelationId, but generates
+// a new correlation ID when the context does not carry one. The returned
+// context holds the correlation ID in use, so pass it on to keep the same ID
+// across all log lines of a request.
+func (l *CLogger) WithOrNewContextCorrelationId(ctx context.Context) (*CLogger, context.Context) {
+	ctx = contextWithOrNewCorrelationId(ctx, l.keys().contextKey)
+	return l.WithContextCorrelationId(ctx), ctx
+}
+
+// WithOrNewContextCorrelationId is like WithContextCorrelationId, but generates
+// a new correlation ID when the context does not carry one. The returned
+// context holds the correlation ID in use, so pass it on to keep the same ID
+// across all log lines of a request.
+func (l *CSugaredLogger) WithOrNewContextCorrelationId(ctx context.Context) (*CSugaredLogger, context.Context) {
+	ctx = contextWithOrNewCorrelationId(ctx, l.keys().contextKey)
+	return l.WithContextCorrelationId(ctx), ctx
+}
+
+// contextWithOrNewCorrelationId returns ctx if it carries a correlation ID
+// (see contextCorrelationId), or a child context carrying a newly generated
+// one.
+func contextWithOrNewCorrelationId(ctx context.Context, contextKey interface{}) context.Context {
+	if contextCorrelationId(ctx, contextKey) != nil {
+		return ctx
+	}
+	return ContextWithCorrelationId(ctx, NewCorrelationId())
+}
+
+// NewCorrelationId returns a new correlation ID, a random (version 4) UUID, as
+// generated by the middlewares when a request carries none.
+func NewCorrelationId() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("correlation ID generation error: %s", err.Error()))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSetCorrelationIdContextKey(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	out := newMemorySink(t, "correlation-key")
+	if err := InitWithOptionsE(context.Background(), Options{Quiet: true, OutputPaths: []string{"memory://correlation-key"}}); err != nil {
+		t.Fatal(err)
+	}
+	defer globalCorrelationKeys.Store(Options{}.correlationKeys())
+
+	stored := ContextWithCorrelationId(context.Background(), "stored")
+	filled := context.WithValue(context.Background(), "correlation_id", "filled")
+	derived := Logger().Named("derived")
+	independent, err := New(Options{OutputPaths: []string{"memory://correlation-key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetCorrelationIdContextKey("request_id")
+
+	if got, ok := CorrelationIdFromContext(stored); !ok || got != "stored" {
+		t.Errorf("CorrelationIdFromContext = %q, %t, want the ID stored with ContextWithCorrelationId", got, ok)
+	}
+	Logger().WithContextCorrelationId(stored).Info("Global")
+	derived.WithContextCorrelationId(filled).Info("Derived")
+	independent.WithContextCorrelationId(filled).Info("Independent")
+	got := out.String()
+	for _, want := range []string{
+		`"msg":"Global","correlation_id":"stored"`,
+		`"msg":"Derived","correlation_id":"filled"`,
+		`"msg":"Independent","correlation_id":"filled"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, want %s", got, want)
+		}
+	}
+}
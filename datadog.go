@@ -0,0 +1,15 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+// datadogLevelEncoder encodes levels as Datadog log statuses.
+func datadogLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch l {
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		enc.AppendString("critical")
+	case zapcore.FatalLevel:
+		enc.AppendString("emergency")
+	default:
+		enc.AppendString(l.String())
+	}
+}
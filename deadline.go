@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WarnOnDeadline logs a Warn entry when the deadline of ctx is less than
+// threshold away, or already passed, to surface requests running dangerously
+// long. The entry and the returned logger carry the remaining time in the
+// "deadline_remaining" field, so that the next entries show it too. It returns
+// l as is when ctx has no deadline or the deadline is further away.
+//
+//	l := logger.Logger().WarnOnDeadline(ctx, 100*time.Millisecond)
+func (l *CLogger) WarnOnDeadline(ctx context.Context, threshold time.Duration) *CLogger {
+	remaining, ok := deadlineRemaining(ctx, threshold)
+	if !ok {
+		return l
+	}
+	l = l.With(zap.Duration("deadline_remaining", remaining))
+	l.Logger.WithOptions(zap.AddCallerSkip(1)).Warn("Context deadline is near")
+	return l
+}
+
+// WarnOnDeadline logs a Warn entry when the deadline of ctx is less than
+// threshold away, or already passed, and returns an instance of the same
+// logger with the remaining time in the "deadline_remaining" field. It returns
+// l as is when ctx has no deadline or the deadline is further away.
+func (l *CSugaredLogger) WarnOnDeadline(ctx context.Context, threshold time.Duration) *CSugaredLogger {
+	remaining, ok := deadlineRemaining(ctx, threshold)
+	if !ok {
+		return l
+	}
+	l = l.With(zap.Duration("deadline_remaining", remaining))
+	l.SugaredLogger.WithOptions(zap.AddCallerSkip(1)).Warn("Context deadline is near")
+	return l
+}
+
+// deadlineRemaining returns the time left before the deadline of ctx, and
+// whether it is less than threshold.
+func deadlineRemaining(ctx context.Context, threshold time.Duration) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(deadline)
+	return remaining, remaining < threshold
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderFunc builds an encoder from the encoder configuration of the logger,
+// which holds the field keys and the time, level and caller encoders.
+type EncoderFunc func(zapcore.EncoderConfig) (zapcore.Encoder, error)
+
+// newEncoder returns an encoder like the one zap builds for cfg, or the one
+// built by opts.Encoder if not nil, adding the fields opts asks for to every
+// entry and redacting. All the cores of the loggers built by this package
+// encode with it.
+func newEncoder(cfg zap.Config, opts Options) (zapcore.Encoder, error) {
+	var enc zapcore.Encoder
+	switch {
+	case opts.Encoder != nil:
+		var err error
+		if enc, err = opts.Encoder(cfg.EncoderConfig); err != nil {
+			return nil, err
+		}
+	case cfg.Encoding == "json":
+		enc = zapcore.NewJSONEncoder(cfg.EncoderConfig)
+	case cfg.Encoding == "console":
+		enc = zapcore.NewConsoleEncoder(cfg.EncoderConfig)
+	case cfg.Encoding == LogfmtEncoding:
+		enc = newLogfmtEncoder(cfg.EncoderConfig)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", cfg.Encoding)
+	}
+	if opts.SeverityNumber {
+		enc = severityEncoder{enc}
+	}
+	if opts.IncludeGoroutineId {
+		enc = goroutineEncoder{enc}
+	}
+	if opts.IncludePackage {
+		enc = packageEncoder{enc}
+	}
+	return newRedactEncoder(enc), nil
+}
+
+// newOutputCore returns a core writing to ws, the output paths of cfg, to
+// replace the core zap builds from cfg, whose encoder does not redact and which
+// cannot be buffered, and the function stopping the buffering, if any.
+func newOutputCore(cfg zap.Config, opts Options, ws zapcore.WriteSyncer) (zapcore.Core, func(), error) {
+	enc, err := newEncoder(cfg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop := func() {}
+	b := opts.Buffering
+	if b != nil {
+		buffered := &zapcore.BufferedWriteSyncer{
+			WS:            ws,
+			Size:          b.Size,
+			FlushInterval: b.FlushInterval,
+		}
+		ws = buffered
+		stop = func() { _ = buffered.Stop() }
+	}
+	core := zapcore.NewCore(enc, ws, cfg.Level)
+	if b != nil && b.FlushLevel != nil {
+		core = flushOnLevelCore{Core: core, level: *b.FlushLevel}
+	}
+	return teeCore(core, cfg), stop, nil
+}
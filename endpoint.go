@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultLogLevelEndpointAddr is the listen address of the log level endpoint
+// when Options.LogLevelEndpointAddr is empty.
+const defaultLogLevelEndpointAddr = ":53835"
+
+// logLevelEndpointPath is the path the log level handler is mounted on.
+const logLevelEndpointPath = "/loglevel"
+
+var (
+	logLevelEndpointAddr   string
+	logLevelEndpointServer *http.Server
+)
+
+// LogLevelEndpointAddr returns the address the log level endpoint is bound to,
+// e.g. "[::]:53835". When an ephemeral port (":0") was requested this is the
+// port actually chosen by the OS. It returns an empty string if the endpoint is
+// not running.
+func LogLevelEndpointAddr() string {
+	return logLevelEndpointAddr
+}
+
+// logLevelEndpointShutdownTimeout bounds the graceful shutdown of the log
+// level endpoint.
+const logLevelEndpointShutdownTimeout = 5 * time.Second
+
+// serveLogLevel binds addr and serves handler on logLevelEndpointPath in a
+// separate goroutine, until ctx is done. It returns the server and the bound
+// address, or an error if the address could not be bound. Errors occurring
+// while serving are logged on l.
+func serveLogLevel(ctx context.Context, l *zap.Logger, handler http.Handler, addr string) (*http.Server, string, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(logLevelEndpointPath, handler)
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			l.Error("Logger HTTP Server failed", zap.Error(err))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), logLevelEndpointShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			l.Error("Logger HTTP Server shutdown failed", zap.Error(err))
+			return
+		}
+		l.Info("Logger HTTP Server stopped")
+	}()
+	return srv, ln.Addr().String(), nil
+}
+
+// stopLogLevel closes the log level endpoint, if running.
+func stopLogLevel() {
+	if logLevelEndpointServer != nil {
+		_ = logLevelEndpointServer.Close()
+	}
+	logLevelEndpointServer = nil
+	logLevelEndpointAddr = ""
+}
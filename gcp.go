@@ -0,0 +1,29 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+// Special fields of Google Cloud Logging structured logs.
+const (
+	gcpTraceKey  = "logging.googleapis.com/trace"
+	gcpSpanIdKey = "logging.googleapis.com/spanId"
+)
+
+// gcpLevelEncoder encodes levels as Google Cloud Logging severities.
+func gcpLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch l {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
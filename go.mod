@@ -1,5 +1,17 @@
 module github.com/danbordeanu/go-logger
 
-go 1.16
+go 1.21.0
 
-require go.uber.org/zap v1.19.1
+require (
+	go.opentelemetry.io/otel/trace v1.29.0
+	go.uber.org/multierr v1.10.0
+	go.uber.org/zap v1.27.0
+	golang.org/x/term v0.29.0
+	google.golang.org/protobuf v1.36.5
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+)
+
+require (
+	go.opentelemetry.io/otel v1.29.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
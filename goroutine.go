@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// goroutineEncoder is a zapcore.Encoder which adds the ID of the goroutine
+// writing an entry in the "goroutine" field. Entries are encoded in the
+// goroutine which logged them, even when the writes are buffered.
+type goroutineEncoder struct {
+	zapcore.Encoder
+}
+
+func (e goroutineEncoder) Clone() zapcore.Encoder {
+	return goroutineEncoder{e.Encoder.Clone()}
+}
+
+func (e goroutineEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	return e.Encoder.EncodeEntry(ent, append(fields[:len(fields):len(fields)], zap.Uint64("goroutine", goroutineId())))
+}
+
+// goroutineId returns the ID of the current goroutine, parsed from the header
+// of its stack trace, e.g. "goroutine 42 [running]:". It returns 0 if the
+// header cannot be parsed.
+func goroutineId() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
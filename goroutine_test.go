@@ -0,0 +1,18 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIncludeGoroutineId(t *testing.T) {
+	out := newMemorySink(t, "goroutine")
+	l, err := New(Options{IncludeGoroutineId: true, OutputPaths: []string{"memory://goroutine"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("Running")
+	if got := out.String(); !strings.Contains(got, `"goroutine":`) {
+		t.Errorf("output = %q, want a goroutine field", got)
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	levelHooksMu sync.Mutex
+	levelHooks   atomic.Pointer[[]func(zapcore.Entry) error]
+)
+
+// RegisterLevelHook registers a function called for every entry written by the
+// loggers built by this package, e.g. to count entries per level for metrics:
+//
+//	logger.RegisterLevelHook(func(e zapcore.Entry) error {
+//		logsTotal.WithLabelValues(e.Level.String()).Inc()
+//		return nil
+//	})
+//
+// Hooks run synchronously on the logging goroutine, so they must be fast and
+// must not block. They apply to loggers built before and after the call.
+func RegisterLevelHook(hook func(zapcore.Entry) error) {
+	if hook == nil {
+		return
+	}
+	levelHooksMu.Lock()
+	defer levelHooksMu.Unlock()
+	var hooks []func(zapcore.Entry) error
+	if current := levelHooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, hook)
+	levelHooks.Store(&hooks)
+}
+
+// runLevelHooks is installed as a zap hook on every logger and calls the
+// registered level hooks.
+func runLevelHooks(ent zapcore.Entry) error {
+	hooks := levelHooks.Load()
+	if hooks == nil {
+		return nil
+	}
+	var err error
+	for _, hook := range *hooks {
+		err = multierr.Append(err, hook(ent))
+	}
+	return err
+}
+
+var (
+	fatalHooksMu sync.Mutex
+	fatalHooks   atomic.Pointer[[]func(zapcore.Entry)]
+)
+
+// RegisterOnFatal registers a function called when a Fatal entry has been
+// written by the loggers built by this package, right before the process
+// exits, e.g. to flush a crash report to an external service. Hooks run in
+// the order they were registered. They apply to loggers built before and
+// after the call.
+func RegisterOnFatal(hook func(zapcore.Entry)) {
+	if hook == nil {
+		return
+	}
+	fatalHooksMu.Lock()
+	defer fatalHooksMu.Unlock()
+	var hooks []func(zapcore.Entry)
+	if current := fatalHooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, hook)
+	fatalHooks.Store(&hooks)
+}
+
+// fatalHook is installed as the fatal hook of every logger. It calls the
+// registered fatal hooks, then exits like zapcore.WriteThenExit.
+type fatalHook struct{}
+
+func (fatalHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	if hooks := fatalHooks.Load(); hooks != nil {
+		for _, hook := range *hooks {
+			hook(ce.Entry)
+		}
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTPSink configures the shipping of logs to an HTTP collector, in addition
+// to the output paths, for environments without a log agent. Entries are
+// encoded like the other outputs and POSTed in batches, one entry per line.
+//
+// Writing a full batch blocks the logging goroutine until it is delivered,
+// which slows the application down rather than piling up entries when the
+// collector is slow. A batch which cannot be delivered after the retries is
+// written to stderr, so that it is not lost silently. Sync delivers the
+// pending batch, and so do Reset and Reconfigure, which then stop shipping the
+// entries of the replaced logger.
+type HTTPSink struct {
+	// URL of the collector, e.g. "https://logs.example.com/ingest".
+	URL string
+	// Header holds headers added to every request, e.g. for authentication.
+	Header http.Header
+	// BatchSize is the number of entries sent at once. Defaults to 100.
+	BatchSize int
+	// FlushInterval is how often a batch which is not full is sent. Defaults
+	// to 5 seconds.
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries of a failed request, waiting twice
+	// as long before each one, starting with 100 milliseconds. Defaults to 3.
+	MaxRetries int
+	// Client sends the requests. Defaults to a client with a 10 seconds
+	// timeout.
+	Client *http.Client
+}
+
+// newHTTPTeeCore returns the core shipping the entries to the collector of h,
+// to tee with the core built from cfg, using the same encoder, level and
+// sampling, and the function stopping it.
+func newHTTPTeeCore(h HTTPSink, cfg zap.Config, opts Options) (zapcore.Core, func(), error) {
+	enc, err := newEncoder(cfg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws := newHTTPWriteSyncer(h)
+	return teeCore(zapcore.NewCore(enc, ws, cfg.Level), cfg), func() { _ = ws.Close() }, nil
+}
+
+// httpWriteSyncer is a zapcore.WriteSyncer batching the entries written to it
+// and POSTing them to a collector.
+type httpWriteSyncer struct {
+	cfg    HTTPSink
+	client *http.Client
+
+	mu    sync.Mutex
+	batch bytes.Buffer
+	count int
+
+	// sendMu keeps batches in order.
+	sendMu sync.Mutex
+
+	// stop stops the flushing goroutine, which closes done when it returns.
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newHTTPWriteSyncer returns the write syncer for h, and starts flushing it
+// every FlushInterval until it is closed.
+func newHTTPWriteSyncer(h HTTPSink) *httpWriteSyncer {
+	if h.BatchSize <= 0 {
+		h.BatchSize = 100
+	}
+	if h.FlushInterval <= 0 {
+		h.FlushInterval = 5 * time.Second
+	}
+	if h.MaxRetries <= 0 {
+		h.MaxRetries = 3
+	}
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	ws := &httpWriteSyncer{cfg: h, client: client, stop: make(chan struct{}), done: make(chan struct{})}
+	go ws.flushEvery(h.FlushInterval)
+	return ws
+}
+
+// flushEvery sends the pending batch every interval, until ws is closed.
+func (ws *httpWriteSyncer) flushEvery(interval time.Duration) {
+	defer close(ws.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = ws.Sync()
+		case <-ws.stop:
+			return
+		}
+	}
+}
+
+// Close stops the periodic flushing and sends the pending batch.
+func (ws *httpWriteSyncer) Close() error {
+	ws.stopOnce.Do(func() { close(ws.stop) })
+	<-ws.done
+	return ws.Sync()
+}
+
+// Write adds an encoded entry to the batch, and sends the batch if full.
+func (ws *httpWriteSyncer) Write(p []byte) (int, error) {
+	ws.mu.Lock()
+	ws.batch.Write(p)
+	ws.count++
+	var full []byte
+	if ws.count >= ws.cfg.BatchSize {
+		full = ws.take()
+	}
+	ws.mu.Unlock()
+	if full != nil {
+		ws.send(full)
+	}
+	return len(p), nil
+}
+
+// Sync sends the pending batch, if any.
+func (ws *httpWriteSyncer) Sync() error {
+	ws.mu.Lock()
+	batch := ws.take()
+	ws.mu.Unlock()
+	if batch == nil {
+		return nil
+	}
+	return ws.send(batch)
+}
+
+// take returns the pending batch, or nil if empty, and starts a new one. The
+// caller must hold ws.mu.
+func (ws *httpWriteSyncer) take() []byte {
+	if ws.count == 0 {
+		return nil
+	}
+	batch := append([]byte(nil), ws.batch.Bytes()...)
+	ws.batch.Reset()
+	ws.count = 0
+	return batch
+}
+
+// send POSTs batch, retrying with exponential backoff, and writes it to stderr
+// if it cannot be delivered.
+func (ws *httpWriteSyncer) send(batch []byte) error {
+	ws.sendMu.Lock()
+	defer ws.sendMu.Unlock()
+	var err error
+	backoff := 100 * time.Millisecond
+	for attempt := 0; attempt <= ws.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = ws.post(batch); err == nil {
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "logger: http sink: %v, writing the batch to stderr\n", err)
+	_, _ = os.Stderr.Write(batch)
+	return fmt.Errorf("http sink: %w", err)
+}
+
+// post sends batch in a single request.
+func (ws *httpWriteSyncer) post(batch []byte) error {
+	req, err := http.NewRequest(http.MethodPost, ws.cfg.URL, bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	for k, v := range ws.cfg.Header {
+		req.Header[k] = v
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHTTPWriteSyncerClose(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	ws := newHTTPWriteSyncer(HTTPSink{URL: srv.URL, FlushInterval: time.Millisecond})
+	_, _ = ws.Write([]byte("{\"msg\":\"Shipped\"}\n"))
+	if err := ws.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ws.done:
+	default:
+		t.Error("the flushing goroutine is still running")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || bodies[0] != "{\"msg\":\"Shipped\"}\n" {
+		t.Errorf("requests = %q, want the entry once", bodies)
+	}
+}
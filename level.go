@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// atomicLevel is the level of the global logger, shared with the log level
+// endpoint.
+var atomicLevel zap.AtomicLevel
+
+// initLevel is the level the global logger was initialized with.
+var initLevel zapcore.Level
+
+// SetLevel changes the level of the global logger at runtime, e.g. to
+// temporarily enable Debug logs on a signal. It acts on the same level as the
+// log level endpoint. You must have initialized the logger prior to this call.
+func SetLevel(level zapcore.Level) {
+	if logger == nil {
+		panic("logger not initialized. Call Init(ctx)")
+	}
+	atomicLevel.SetLevel(level)
+}
+
+// GetLevel returns the current level of the global logger. You must have
+// initialized the logger prior to this call.
+func GetLevel() zapcore.Level {
+	if logger == nil {
+		panic("logger not initialized. Call Init(ctx)")
+	}
+	return atomicLevel.Level()
+}
+
+// Enabled reports whether the logger writes entries at the given level. Use it
+// to skip expensive field computation when the level is disabled:
+//
+//	if log.Enabled(zap.DebugLevel) {
+//		log.Debug("request", zap.Any("body", expensiveDump()))
+//	}
+func (l *CLogger) Enabled(level zapcore.Level) bool {
+	return l.Core().Enabled(level)
+}
+
+// Enabled reports whether the logger writes entries at the given level.
+func (l *CSugaredLogger) Enabled(level zapcore.Level) bool {
+	return l.SugaredLogger.Desugar().Core().Enabled(level)
+}
+
+// WithTemporaryLevel runs fn with a child logger writing the entries at or
+// above level, whatever the level of l, e.g. to get the Debug logs of a single
+// operation without enabling them for the whole process:
+//
+//	log.WithTemporaryLevel(zap.DebugLevel, func(log *logger.CLogger) {
+//		err = client.Sync(ctx, log)
+//	})
+//
+// l and the global level are left unchanged. Entries below the level of l are
+// written to the regular outputs only, not to syslog, the HTTP sink nor the
+// high severity outputs.
+func (l *CLogger) WithTemporaryLevel(level zapcore.Level, fn func(*CLogger)) {
+	child := l.Logger.With(zap.Field{Key: levelOverrideKey, Type: zapcore.SkipType, Interface: level})
+	child = child.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return minLevelCore{Core: c, level: level}
+	}))
+	fn(&CLogger{Logger: *child, correlationKeys: l.keys(), traceFormat: l.traceFormat})
+}
+
+// levelOverrideKey is the key of the field WithTemporaryLevel passes its level
+// down the cores with, to the levelOverrideCore of the regular outputs.
+const levelOverrideKey = "logger.levelOverride"
+
+// levelOverrideCore wraps the core of the regular outputs, so that a level set
+// by WithTemporaryLevel takes precedence over the level of the logger.
+type levelOverrideCore struct {
+	zapcore.Core
+	level *zapcore.Level
+}
+
+func (c levelOverrideCore) Enabled(level zapcore.Level) bool {
+	if c.level != nil {
+		return level >= *c.level
+	}
+	return c.Core.Enabled(level)
+}
+
+func (c levelOverrideCore) With(fields []zapcore.Field) zapcore.Core {
+	var kept []zapcore.Field
+	for i, f := range fields {
+		if f.Key != levelOverrideKey || f.Type != zapcore.SkipType {
+			if kept != nil {
+				kept = append(kept, f)
+			}
+			continue
+		}
+		if level, ok := f.Interface.(zapcore.Level); ok {
+			c.level = &level
+		}
+		if kept == nil {
+			kept = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+	}
+	if kept != nil {
+		fields = kept
+	}
+	return levelOverrideCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c levelOverrideCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes to the wrapped core directly, as its Check would drop the
+// entries below its level.
+func (c levelOverrideCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, fields)
+}
+
+// minLevelCore drops the entries below level, for WithTemporaryLevel to
+// raise the level of a logger.
+type minLevelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c minLevelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.level && c.Core.Enabled(level)
+}
+
+func (c minLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return minLevelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c minLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.level {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
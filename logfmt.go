@@ -0,0 +1,304 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogfmtEncoding is the name the logfmt encoder of this package is registered
+// under in zap, which is the Encoding of Config() with the "logfmt" encoding,
+// e.g. for NewFromConfig. It is not "logfmt", which other packages register.
+const LogfmtEncoding = "go-logger-logfmt"
+
+func init() {
+	// the name is ours, but an error must not make the package unimportable:
+	// Options do not depend on the registration
+	_ = zap.RegisterEncoder(LogfmtEncoding, func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return newRedactEncoder(newLogfmtEncoder(cfg)), nil
+	})
+}
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder is a zapcore.Encoder writing entries as logfmt key=value pairs,
+// honoring the keys and encoders of the EncoderConfig. Fields of nested objects
+// and namespaces are flattened with dotted keys, arrays and reflected values
+// are written as JSON. Values containing spaces, quotes, equal signs or control
+// characters are quoted.
+type logfmtEncoder struct {
+	cfg    *zapcore.EncoderConfig
+	buf    *buffer.Buffer
+	prefix string
+}
+
+// newLogfmtEncoder returns a logfmt encoder for cfg.
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) *logfmtEncoder {
+	return &logfmtEncoder{cfg: &cfg, buf: logfmtPool.Get()}
+}
+
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get(), prefix: e.prefix}
+	_, _ = clone.buf.Write(e.buf.Bytes())
+	return clone
+}
+
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get()}
+
+	if e.cfg.TimeKey != "" && e.cfg.EncodeTime != nil {
+		final.addEncoded(e.cfg.TimeKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(ent.Time, enc) })
+	}
+	if e.cfg.LevelKey != "" && e.cfg.EncodeLevel != nil {
+		final.addEncoded(e.cfg.LevelKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeLevel(ent.Level, enc) })
+	}
+	if e.cfg.NameKey != "" && ent.LoggerName != "" {
+		nameEncoder := e.cfg.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		final.addEncoded(e.cfg.NameKey, func(enc zapcore.PrimitiveArrayEncoder) { nameEncoder(ent.LoggerName, enc) })
+	}
+	if ent.Caller.Defined {
+		if e.cfg.CallerKey != "" && e.cfg.EncodeCaller != nil {
+			final.addEncoded(e.cfg.CallerKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeCaller(ent.Caller, enc) })
+		}
+		if e.cfg.FunctionKey != "" {
+			final.AddString(e.cfg.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if e.cfg.MessageKey != "" {
+		final.AddString(e.cfg.MessageKey, ent.Message)
+	}
+	if e.buf.Len() > 0 {
+		final.separate()
+		_, _ = final.buf.Write(e.buf.Bytes())
+	}
+	final.prefix = e.prefix
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	final.prefix = ""
+	if ent.Stack != "" && e.cfg.StacktraceKey != "" {
+		final.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+	if e.cfg.LineEnding != "" {
+		final.buf.AppendString(e.cfg.LineEnding)
+	} else {
+		final.buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	return final.buf, nil
+}
+
+// separate writes the space separating two pairs, if needed.
+func (e *logfmtEncoder) separate() {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+}
+
+// addKey writes the key of a pair, with the current namespace prefix.
+func (e *logfmtEncoder) addKey(key string) {
+	e.separate()
+	e.buf.AppendString(logfmtKey(e.prefix + key))
+	e.buf.AppendByte('=')
+}
+
+// add writes a key=value pair, quoting the value if needed.
+func (e *logfmtEncoder) add(key, value string) {
+	e.addKey(key)
+	e.buf.AppendString(logfmtValue(value))
+}
+
+// addEncoded writes a pair whose value is produced by a zapcore encoder
+// function, such as a time or level encoder.
+func (e *logfmtEncoder) addEncoded(key string, encode func(zapcore.PrimitiveArrayEncoder)) {
+	var v logfmtPrimitive
+	encode(&v)
+	e.add(key, v.String())
+}
+
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.AddReflected(key, m.Fields[key])
+}
+
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	prefix := e.prefix
+	e.prefix = prefix + key + "."
+	err := obj.MarshalLogObject(e)
+	e.prefix = prefix
+	return err
+}
+
+func (e *logfmtEncoder) AddBinary(key string, value []byte) {
+	e.add(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (e *logfmtEncoder) AddByteString(key string, value []byte) {
+	e.add(key, string(value))
+}
+
+func (e *logfmtEncoder) AddBool(key string, value bool) {
+	e.add(key, strconv.FormatBool(value))
+}
+
+func (e *logfmtEncoder) AddComplex128(key string, value complex128) {
+	e.add(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+func (e *logfmtEncoder) AddComplex64(key string, value complex64) {
+	e.add(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+func (e *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	if e.cfg.EncodeDuration == nil {
+		e.add(key, value.String())
+		return
+	}
+	e.addEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeDuration(value, enc) })
+}
+
+func (e *logfmtEncoder) AddFloat64(key string, value float64) {
+	e.add(key, logfmtFloat(value, 64))
+}
+
+func (e *logfmtEncoder) AddFloat32(key string, value float32) {
+	e.add(key, logfmtFloat(float64(value), 32))
+}
+
+func (e *logfmtEncoder) AddInt(key string, value int)     { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt8(key string, value int8)   { e.AddInt64(key, int64(value)) }
+
+func (e *logfmtEncoder) AddInt64(key string, value int64) {
+	e.add(key, strconv.FormatInt(value, 10))
+}
+
+func (e *logfmtEncoder) AddString(key, value string) {
+	e.add(key, value)
+}
+
+func (e *logfmtEncoder) AddTime(key string, value time.Time) {
+	if e.cfg.EncodeTime == nil {
+		e.add(key, value.Format(time.RFC3339Nano))
+		return
+	}
+	e.addEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(value, enc) })
+}
+
+func (e *logfmtEncoder) AddUint(key string, value uint)       { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint32(key string, value uint32)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint16(key string, value uint16)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint8(key string, value uint8)     { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+func (e *logfmtEncoder) AddUint64(key string, value uint64) {
+	e.add(key, strconv.FormatUint(value, 10))
+}
+
+func (e *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	if s, ok := value.(string); ok {
+		e.add(key, s)
+		return nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	e.add(key, string(b))
+	return nil
+}
+
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+}
+
+// logfmtKey replaces the characters which are not allowed in logfmt keys.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue quotes value if it is empty or contains characters which would
+// break the pair.
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
+
+// logfmtFloat formats a float like the JSON encoder does, including special
+// values.
+func logfmtFloat(value float64, bitSize int) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, bitSize)
+}
+
+// logfmtPrimitive captures the values appended by zapcore encoder functions,
+// joining them with commas.
+type logfmtPrimitive struct {
+	strings.Builder
+}
+
+func (p *logfmtPrimitive) append(s string) {
+	if p.Len() > 0 {
+		p.WriteByte(',')
+	}
+	p.WriteString(s)
+}
+
+func (p *logfmtPrimitive) AppendBool(v bool)         { p.append(strconv.FormatBool(v)) }
+func (p *logfmtPrimitive) AppendByteString(v []byte) { p.append(string(v)) }
+func (p *logfmtPrimitive) AppendComplex128(v complex128) {
+	p.append(strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (p *logfmtPrimitive) AppendComplex64(v complex64) {
+	p.append(strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (p *logfmtPrimitive) AppendFloat64(v float64) { p.append(logfmtFloat(v, 64)) }
+func (p *logfmtPrimitive) AppendFloat32(v float32) { p.append(logfmtFloat(float64(v), 32)) }
+func (p *logfmtPrimitive) AppendInt(v int)         { p.append(strconv.FormatInt(int64(v), 10)) }
+func (p *logfmtPrimitive) AppendInt64(v int64)     { p.append(strconv.FormatInt(v, 10)) }
+func (p *logfmtPrimitive) AppendInt32(v int32)     { p.append(strconv.FormatInt(int64(v), 10)) }
+func (p *logfmtPrimitive) AppendInt16(v int16)     { p.append(strconv.FormatInt(int64(v), 10)) }
+func (p *logfmtPrimitive) AppendInt8(v int8)       { p.append(strconv.FormatInt(int64(v), 10)) }
+func (p *logfmtPrimitive) AppendString(v string)   { p.append(v) }
+func (p *logfmtPrimitive) AppendUint(v uint)       { p.append(strconv.FormatUint(uint64(v), 10)) }
+func (p *logfmtPrimitive) AppendUint64(v uint64)   { p.append(strconv.FormatUint(v, 10)) }
+func (p *logfmtPrimitive) AppendUint32(v uint32)   { p.append(strconv.FormatUint(uint64(v), 10)) }
+func (p *logfmtPrimitive) AppendUint16(v uint16)   { p.append(strconv.FormatUint(uint64(v), 10)) }
+func (p *logfmtPrimitive) AppendUint8(v uint8)     { p.append(strconv.FormatUint(uint64(v), 10)) }
+func (p *logfmtPrimitive) AppendUintptr(v uintptr) { p.append(strconv.FormatUint(uint64(v), 10)) }
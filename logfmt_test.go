@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogfmtEncoding(t *testing.T) {
+	// the name other logfmt packages register is left to them
+	if err := zap.RegisterEncoder("logfmt", func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return zapcore.NewJSONEncoder(cfg), nil
+	}); err != nil {
+		t.Errorf("registering the logfmt encoding: %v", err)
+	}
+
+	out := newMemorySink(t, "logfmt")
+	cfg := zap.NewProductionConfig()
+	cfg.Encoding = LogfmtEncoding
+	cfg.OutputPaths = []string{"memory://logfmt"}
+	l, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("Logfmt", zap.String("user", "alice"))
+	if got := out.String(); !strings.Contains(got, "msg=Logfmt user=alice") {
+		t.Errorf("output = %q, want logfmt", got)
+	}
+}
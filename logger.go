@@ -2,60 +2,253 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// CSugaredLogger is a superset of zap.SugaredLogger
+// CSugaredLogger is a superset of zap.SugaredLogger. The methods of
+// zap.SugaredLogger, such as Infow, are available as is, and the fields added
+// with the With methods of CSugaredLogger are logged with theirs.
 type CSugaredLogger struct {
 	zap.SugaredLogger
+	correlationKeys *correlationIdKeys
+	traceFormat     *traceFormat
 }
 
-// CLogger is a superset of zap.Logger
+// CLogger is a superset of zap.Logger. The methods of zap.Logger are available
+// as is, e.g. Check for the fastest conditional logging, which skips building
+// fields when the level is disabled:
+//
+//	if ce := log.Check(zap.DebugLevel, "Cache miss"); ce != nil {
+//		ce.Write(zap.String("key", key))
+//	}
 type CLogger struct {
 	zap.Logger
+	correlationKeys *correlationIdKeys
+	traceFormat     *traceFormat
 }
 
 var logger *CLogger
-var correlationIdContextKey string
-var correlationIdFieldKey string
+
+// initConfig is the zap.Config the global logger was built with.
+var initConfig zap.Config
+
+// initEnvironment is the Environment the global logger was built with.
+var initEnvironment string
+
+// initCtx is derived from the context passed to Init. Goroutines started on
+// behalf of the global logger stop when it is done, which Reset forces by
+// calling initCancel.
+var (
+	initCtx    context.Context
+	initCancel context.CancelFunc
+)
+
+// ErrNotInitialized is returned by functions which need the global logger
+// when Init has not been called yet.
+var ErrNotInitialized = errors.New("logger not initialized")
 
 // WithContextCorrelationId returns an instance of the same logger with the correlation ID taken from the context added to it.
 func (l *CLogger) WithContextCorrelationId(ctx context.Context) *CLogger {
-	correlationId := ctx.Value(correlationIdContextKey)
+	correlationId := contextCorrelationId(ctx, l.keys().contextKey)
 	return l.WithCorrelationId(correlationId)
 }
 
 // WithContextCorrelationId returns an instance of the same logger with the correlation ID taken from the context added to it.
 func (l *CSugaredLogger) WithContextCorrelationId(ctx context.Context) *CSugaredLogger {
-	correlationId := ctx.Value(correlationIdContextKey)
+	correlationId := contextCorrelationId(ctx, l.keys().contextKey)
 	return l.WithCorrelationId(correlationId)
 }
 
 // WithCorrelationId returns an instance of the same logger with the correlation ID field added to it.
+// Besides strings, the ID may be a []byte, an integer or a fmt.Stringer such as a UUID; any other
+// non-nil value is formatted with %v. A nil or empty ID returns l as is, without allocating.
 func (l *CLogger) WithCorrelationId(correlationId interface{}) *CLogger {
-	if s, ok := correlationId.(string); ok {
-		return &CLogger{*l.Logger.With(zap.Stringp(correlationIdFieldKey, &s))}
+	if s, ok := correlationIdString(correlationId); ok && s != "" {
+		return &CLogger{Logger: *l.Logger.With(zap.String(l.keys().fieldKey, s)), correlationKeys: l.keys(), traceFormat: l.traceFormat}
 	}
 	return l
 }
 
 // WithCorrelationId returns an instance of the same logger with the correlation ID field added to it.
+// Besides strings, the ID may be a []byte, an integer or a fmt.Stringer such as a UUID; any other
+// non-nil value is formatted with %v. A nil or empty ID returns l as is, without allocating.
 func (l *CSugaredLogger) WithCorrelationId(correlationId interface{}) *CSugaredLogger {
-	if s, ok := correlationId.(string); ok {
-		return &CSugaredLogger{*l.SugaredLogger.With(zap.Stringp(correlationIdFieldKey, &s))}
+	if s, ok := correlationIdString(correlationId); ok && s != "" {
+		return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.With(zap.String(l.keys().fieldKey, s)), correlationKeys: l.keys(), traceFormat: l.traceFormat}
 	}
 	return l
 }
 
+// With returns an instance of the same logger with fields added to it.
 func (l *CLogger) With(args ...zap.Field) *CLogger {
-	return &CLogger{*l.Logger.With(args...)}
+	return &CLogger{Logger: *l.Logger.With(args...), correlationKeys: l.keys(), traceFormat: l.traceFormat}
 }
 
+// With returns an instance of the same logger with args added to it, taken as
+// in Infow and the other *w methods: key-value pairs, or zap.Field values. The
+// fields add up with the correlation ID and the key-value pairs of each entry:
+//
+//	log := logger.SugaredLogger().WithContextCorrelationId(ctx).With("order_id", id)
+//	log.Infow("Order shipped", "carrier", carrier)
+//
+// A key without a value, e.g. an odd number of args, is left out and reported
+// in an Error entry rather than panicking, in development mode too.
 func (l *CSugaredLogger) With(args ...interface{}) *CSugaredLogger {
-	return &CSugaredLogger{*l.SugaredLogger.With(args...)}
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.With(args...), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithMap returns an instance of the same logger with a field added for every
+// entry of m, converted with zap.Any, in the order of the keys. It is handy for
+// metadata assembled dynamically.
+func (l *CLogger) WithMap(m map[string]interface{}) *CLogger {
+	if len(m) == 0 {
+		return l
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.Any(k, m[k]))
+	}
+	return l.With(fields...)
+}
+
+// WithLazy is like With, but the fields are only evaluated, once, when the
+// first entry is actually written, e.g. for costly debug fields on a logger
+// which mostly logs at Info level:
+//
+//	l := logger.Logger().WithLazy(zap.Object("request", req))
+func (l *CLogger) WithLazy(args ...zap.Field) *CLogger {
+	return &CLogger{Logger: *l.Logger.WithLazy(args...), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// Named returns a sub-logger with name appended to the logger's name, e.g. "db"
+// or "http". Names are joined with periods and written in the "logger" field.
+func (l *CLogger) Named(name string) *CLogger {
+	return &CLogger{Logger: *l.Logger.Named(name), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// Named returns a sub-logger with name appended to the logger's name, e.g. "db"
+// or "http". Names are joined with periods and written in the "logger" field.
+func (l *CSugaredLogger) Named(name string) *CSugaredLogger {
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.Named(name), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithCallerSkip returns an instance of the same logger which skips n more
+// stack frames when reporting the caller. Use it in your own logging helpers so
+// that the caller field points at their callers rather than at the helpers.
+func (l *CLogger) WithCallerSkip(n int) *CLogger {
+	return &CLogger{Logger: *l.Logger.WithOptions(zap.AddCallerSkip(n)), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithCallerSkip returns an instance of the same logger which skips n more
+// stack frames when reporting the caller. Use it in your own logging helpers so
+// that the caller field points at their callers rather than at the helpers.
+func (l *CSugaredLogger) WithCallerSkip(n int) *CSugaredLogger {
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.Desugar().WithOptions(zap.AddCallerSkip(n)).Sugar(), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithOptions returns an instance of the same logger with the zap options
+// applied, e.g. zap.Fields, zap.AddStacktrace or zap.WrapCore, in one go:
+//
+//	l := logger.Logger().WithOptions(zap.Fields(zap.String("component", "db")), zap.AddStacktrace(zap.WarnLevel))
+func (l *CLogger) WithOptions(opts ...zap.Option) *CLogger {
+	return &CLogger{Logger: *l.Logger.WithOptions(opts...), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithOptions returns an instance of the same logger with the zap options
+// applied, e.g. zap.Fields, zap.AddStacktrace or zap.WrapCore, in one go.
+func (l *CSugaredLogger) WithOptions(opts ...zap.Option) *CSugaredLogger {
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.WithOptions(opts...), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// Sugar returns the sugared logger wrapping the same core, keeping the
+// correlation ID keys. Converting is cheap, so switch to the sugared API for a
+// single call site if it is handier.
+func (l *CLogger) Sugar() *CSugaredLogger {
+	return &CSugaredLogger{SugaredLogger: *l.Logger.Sugar(), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// Desugar returns the sugar-free logger wrapping the same core, keeping the
+// correlation ID keys.
+func (l *CSugaredLogger) Desugar() *CLogger {
+	return &CLogger{Logger: *l.SugaredLogger.Desugar(), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// ErrorReturn logs msg at Error level with err and fields added, and returns
+// err wrapped with msg, so that logging an error and returning it is a single
+// statement which keeps both in sync:
+//
+//	if err != nil {
+//		return log.ErrorReturn("Failed to save the order", err, zap.String("order_id", id))
+//	}
+//
+// A nil err logs nothing and returns nil.
+func (l *CLogger) ErrorReturn(msg string, err error, fields ...zap.Field) error {
+	if err == nil {
+		return nil
+	}
+	if ce := l.Logger.WithOptions(zap.AddCallerSkip(1)).Check(zap.ErrorLevel, msg); ce != nil {
+		ce.Write(append(fields[:len(fields):len(fields)], zap.Error(err))...)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// Muted returns an instance of the same logger which discards everything, to
+// quiet a noisy code path without changing the level of the other loggers.
+func (l *CLogger) Muted() *CLogger {
+	return l.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return zapcore.NewNopCore()
+	}))
+}
+
+// Muted returns an instance of the same logger which discards everything.
+func (l *CSugaredLogger) Muted() *CSugaredLogger {
+	return l.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return zapcore.NewNopCore()
+	}))
+}
+
+// Err returns an instance of the same logger with err added in the "error"
+// field, so that errors are logged under the same key everywhere:
+//
+//	logger.Logger().Err(err).Error("Failed to save the order")
+//
+// A nil err is ignored.
+func (l *CLogger) Err(err error) *CLogger {
+	if err == nil {
+		return l
+	}
+	return &CLogger{Logger: *l.Logger.With(zap.Error(err)), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// Err returns an instance of the same logger with err added in the "error"
+// field, so that errors are logged under the same key everywhere. A nil err is
+// ignored.
+func (l *CSugaredLogger) Err(err error) *CSugaredLogger {
+	if err == nil {
+		return l
+	}
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.With(zap.Error(err)), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithError is the same as Err, named after the With helpers of the sugared
+// logger, for chaining with the level helpers:
+//
+//	logger.SugaredLogger().WithError(err).Errorw("Failed to save the order", "order_id", id)
+func (l *CSugaredLogger) WithError(err error) *CSugaredLogger {
+	return l.Err(err)
 }
 
 // SugaredLogger returns an instance of the sugared logger. You must have initialized the logger prior to this call.
@@ -63,8 +256,7 @@ func SugaredLogger() *CSugaredLogger {
 	if logger == nil {
 		panic("logger not initialized. Call Init(ctx)")
 	}
-	l := logger.Sugar()
-	return &CSugaredLogger{*l}
+	return logger.Sugar()
 }
 
 // Logger returns an instance of the sugar-free logger. You must have initialized the logger prior to this call.
@@ -75,20 +267,93 @@ func Logger() *CLogger {
 	return logger
 }
 
-// SetCorrelationIdFieldKey sets the correlation ID field key in JSON responses. By default, it is "correlation_id"
+// initMu serializes the initialization of the global logger.
+var initMu sync.Mutex
+
+// initialized holds the global logger once InitWithOptionsE has returned, for
+// L to read it without racing with a concurrent initialization.
+var initialized atomic.Pointer[CLogger]
+
+// L returns the global logger like Logger, but initializes it with the
+// production defaults of InitWithOptions and a zero Options if Init has not
+// been called yet, instead of panicking. Use it in code which may run before
+// Init, e.g. in package init functions; keep Logger() to catch a missing Init.
+// It is safe for concurrent use, including with Init.
+func L() *CLogger {
+	if l := initialized.Load(); l != nil {
+		return l
+	}
+	_ = InitWithOptionsE(context.Background(), Options{})
+	if l := initialized.Load(); l != nil {
+		return l
+	}
+	return NewNop()
+}
+
+// Sync flushes any buffered log entries of the global logger. Defer it in main
+// right after Init so the last lines are not lost when the process exits:
+//
+//	logger.Init(ctx, false, false)
+//	defer logger.Sync()
+//
+// Unlike Logger(), it returns an error instead of panicking when the logger is
+// not initialized, so it is safe to defer during a failed startup.
+func Sync() error {
+	if logger == nil {
+		return fmt.Errorf("sync: %w", ErrNotInitialized)
+	}
+	return multierr.Append(logger.Sync(), auditLogger.Sync())
+}
+
+// Reset discards the global logger and stops the log level endpoint, if any,
+// so that Init can be called again with a different configuration. Buffered
+// entries are flushed and the outputs closed before the logger is discarded.
+//
+// Reset is intended for tests only and is not safe for concurrent use: it must
+// not be called while other goroutines are logging or initializing.
+func Reset() {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if logger == nil {
+		return
+	}
+	_ = logger.Sync()
+	_ = auditLogger.Sync()
+	initCancel()
+	stopLogLevel()
+	globalClose()
+	globalErrorOutput = nil
+	logger = nil
+	initialized.Store(nil)
+	auditLogger = nil
+	panicDedupe = nil
+	initEnvironment = ""
+}
+
+// SetCorrelationIdFieldKey sets the correlation ID field key in JSON responses of the global logger and of the
+// loggers derived from it from then on. By default, it is "correlation_id". Loggers derived before keep the keys in
+// use when they were derived, and loggers built with New keep their own keys.
 func SetCorrelationIdFieldKey(key string) {
 	if key == "" {
 		return
 	}
-	correlationIdFieldKey = key
+	keys := *globalCorrelationKeys.Load()
+	keys.fieldKey = key
+	globalCorrelationKeys.Store(&keys)
 }
 
-// SetCorrelationIdContextKey sets the correlation ID context key. By default, it is "correlation_id"
+// SetCorrelationIdContextKey sets the string context key under which the global logger and the loggers derived from
+// it from then on also look for a correlation ID, for contexts filled by the application itself rather than with
+// ContextWithCorrelationId. By default, it is "correlation_id". Loggers derived before keep the keys in use when they
+// were derived, and loggers built with New keep their own keys. Correlation IDs stored with ContextWithCorrelationId
+// are found whatever the key.
 func SetCorrelationIdContextKey(key string) {
 	if key == "" {
 		return
 	}
-	correlationIdContextKey = key
+	keys := *globalCorrelationKeys.Load()
+	keys.contextKey = key
+	globalCorrelationKeys.Store(&keys)
 }
 
 // Init bootstraps the logger. You must call this method just once at the
@@ -99,28 +364,283 @@ func SetCorrelationIdContextKey(key string) {
 // correlation ID to your logs.
 //
 // If enableLogLevelEndpoint is true, then an HTTP endpoint on port 53835 at
-// /logger is exposed which can be used to change the log level dynamically. See
-// the Zap documentation for more information.
+// /loglevel is exposed which can be used to change the log level dynamically.
+// See the Zap documentation for more information. Use InitWithOptions to listen
+// on a different address. The endpoint is shut down when ctx is done.
+//
+// If developmentMode is true, then the logLevel is set to Debug, caller
+// fields are more explicit and logs are written in human-readable console
+// format. Do not enable this in production.
 //
-// If developmentMode is true, then the logLevel is set to Debug and caller
-// fields are more explicit. Do not enable this in production.
+// Init panics if the logger cannot be built. Use InitE to handle the error
+// instead.
 func Init(ctx context.Context, enableLogLevelEndpoint, developmentMode bool) {
+	InitWithOptions(ctx, Options{
+		EnableLogLevelEndpoint: enableLogLevelEndpoint,
+		DevelopmentMode:        developmentMode,
+	})
+}
+
+// InitE is like Init, but returns an error instead of panicking. See
+// InitWithOptionsE for the errors it may return.
+func InitE(ctx context.Context, enableLogLevelEndpoint, developmentMode bool) error {
+	return InitWithOptionsE(ctx, Options{
+		EnableLogLevelEndpoint: enableLogLevelEndpoint,
+		DevelopmentMode:        developmentMode,
+	})
+}
+
+// InitWithOptions bootstraps the logger like Init, but takes an Options struct
+// which allows finer control over the configuration, e.g. where the logs are
+// written to. Empty output paths default to stdout.
+//
+// InitWithOptions panics if the logger cannot be built. A log level endpoint
+// which fails to start is logged at Error level but is not fatal.
+func InitWithOptions(ctx context.Context, opts Options) {
+	if err := InitWithOptionsE(ctx, opts); err != nil && initialized.Load() == nil {
+		panic(fmt.Sprintf("logger initalization error: %s", err.Error()))
+	}
+}
+
+// InitWithOptionsE is like InitWithOptions, but returns an error instead of
+// panicking. The error tells which stage failed: "config" for invalid options,
+// "build" when zap could not build the logger (e.g. an output path could not
+// be opened) and "endpoint" when the log level endpoint could not listen. In
+// the latter case the logger is initialized and usable nonetheless.
+//
+// Calling it again once the logger is initialized is a no-op returning nil.
+// Concurrent calls are serialized.
+func InitWithOptionsE(ctx context.Context, opts Options) error {
+	initMu.Lock()
+	defer initMu.Unlock()
 	if logger != nil {
+		return nil
+	}
+
+	globalCorrelationKeys.Store(opts.correlationKeys())
+	globalTraceFormat.Store(opts.traceFormat())
+
+	g, err := buildGlobal(opts, zap.NewAtomicLevel())
+	if err != nil {
+		return err
+	}
+	current, auditCurrent := new(atomic.Pointer[zapcore.Core]), new(atomic.Pointer[zapcore.Core])
+	errorOutput := &swapWriteSyncer{}
+	errorOutput.current.Store(&g.errorOutput)
+	l := g.logger.WithOptions(zap.ErrorOutput(errorOutput), zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		current.Store(&c)
+		return &swapCore{current: current}
+	}))
+	audit := g.audit.WithOptions(zap.ErrorOutput(errorOutput), zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		auditCurrent.Store(&c)
+		return &swapCore{current: auditCurrent}
+	}))
+	zapConfig, loggerMode := g.config, g.modes
+	atom := zapConfig.Level
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+
+	var endpointErr error
+	if opts.EnableLogLevelEndpoint {
+		srv, addr, err := serveLogLevel(ctx, l, atom, opts.logLevelEndpointAddr())
+		if err != nil {
+			l.Error("Logger HTTP Server failed to start", zap.Error(err))
+			endpointErr = fmt.Errorf("logger endpoint: %w", err)
+		} else {
+			loggerMode = append(loggerMode, "serveHttp")
+			logLevelEndpointServer = srv
+			logLevelEndpointAddr = addr
+		}
+	}
+
+	if !opts.Quiet {
+		l.Info("Logger initialized successfully", zap.Strings("logger_modes", loggerMode))
+		if logLevelEndpointAddr != "" {
+			l.Info("Logger HTTP Server active on " + logLevelEndpointAddr + logLevelEndpointPath)
+		}
+	}
+
+	logger = &CLogger{Logger: *l}
+	auditLogger = &CLogger{Logger: *audit}
+	atomicLevel = atom
+	initConfig = zapConfig
+	globalCore = current
+	auditCore = auditCurrent
+	globalErrorOutput = errorOutput
+	globalClose = g.close
+	initEnvironment = opts.Environment
+	initLevel = atom.Level()
+	if opts.PanicDedupWindow > 0 {
+		panicDedupe = newPanicDeduper(opts.PanicDedupWindow)
+	}
+	initCtx, initCancel = ctx, cancel
+	if !opts.DisableShutdownLog {
+		go logShutdown(parent, ctx, l, time.Now())
+	}
+	initialized.Store(logger)
+	return endpointErr
+}
+
+// logShutdown logs the uptime and flushes l once ctx is done because parent,
+// the context passed to Init, is. It stays silent when only ctx is cancelled,
+// i.e. on Reset.
+func logShutdown(parent, ctx context.Context, l *zap.Logger, start time.Time) {
+	<-ctx.Done()
+	if parent.Err() == nil {
 		return
 	}
+	l.Info("Logger shutting down", zap.Duration("uptime", time.Since(start)))
+	_ = l.Sync()
+}
+
+// New builds a logger from opts without touching the global logger, for users
+// who prefer to inject the logger as a dependency rather than rely on Init and
+// Logger(). The returned error is the same as InitWithOptionsE's.
+//
+// The log level endpoint and SetLevel are only available for the global
+// logger, so EnableLogLevelEndpoint is ignored and the level of the returned
+// logger is fixed. Its outputs stay open until the process exits.
+func New(opts Options) (*CLogger, error) {
+	l, _, _, _, err := build(opts, zap.NewAtomicLevel(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CLogger{Logger: *l, correlationKeys: opts.correlationKeys(), traceFormat: opts.traceFormat()}, nil
+}
+
+// NewFromConfig builds a logger from a zap.Config without touching the global
+// logger, e.g. from a copy of Config() tuned beyond what Options allows. Unlike
+// New, it uses zap's defaults for everything which is not in cfg, such as the
+// level hooks, and only redacts with the LogfmtEncoding encoding.
+func NewFromConfig(cfg zap.Config, opts ...zap.Option) (*CLogger, error) {
+	l, err := cfg.Build(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("logger build: %w", err)
+	}
+	return &CLogger{Logger: *l}, nil
+}
+
+// Environment returns the deployment environment the global logger was
+// initialized with, e.g. "staging", or "" if none was set.
+func Environment() string {
+	return initEnvironment
+}
+
+// Config returns a copy of the zap.Config the global logger was built with.
+// Features which zap.Config cannot express, such as syslog delivery or a custom
+// stacktrace level, are not reflected in it. Its Level is shared with the global
+// logger, so replace it if you build another logger from the copy. You must
+// have initialized the logger prior to this call.
+func Config() zap.Config {
+	if logger == nil {
+		panic("logger not initialized. Call Init(ctx)")
+	}
+	cfg := initConfig
+	cfg.OutputPaths = append([]string(nil), initConfig.OutputPaths...)
+	cfg.ErrorOutputPaths = append([]string(nil), initConfig.ErrorOutputPaths...)
+	if initConfig.Sampling != nil {
+		sampling := *initConfig.Sampling
+		cfg.Sampling = &sampling
+	}
+	if initConfig.InitialFields != nil {
+		cfg.InitialFields = make(map[string]interface{}, len(initConfig.InitialFields))
+		for k, v := range initConfig.InitialFields {
+			cfg.InitialFields[k] = v
+		}
+	}
+	return cfg
+}
+
+// sharedOutputs are the outputs of the global logger, opened once and shared
+// with the audit logger. A nil output is opened by each logger.
+type sharedOutputs struct {
+	output, errorOutput zapcore.WriteSyncer
+}
+
+// globalLoggers are the global and audit loggers built by buildGlobal.
+type globalLoggers struct {
+	logger, audit *zap.Logger
+	// config and modes are those of the global logger.
+	config zap.Config
+	modes  []string
+	// errorOutput is the error output of both loggers.
+	errorOutput zapcore.WriteSyncer
+	// close closes the outputs of both loggers.
+	close func()
+}
+
+// buildGlobal builds the global logger from opts like build, and the audit
+// logger, which shares its error output, and its outputs unless opts has
+// AuditOutputPaths.
+func buildGlobal(opts Options, atom zap.AtomicLevel) (*globalLoggers, error) {
+	errorOutput, closeErrorOutput, err := zap.Open(opts.errorOutputPaths()...)
+	if err != nil {
+		return nil, fmt.Errorf("logger build: error output: %w", err)
+	}
+	shared := &sharedOutputs{errorOutput: errorOutput}
+	closeShared := closeErrorOutput
+	if len(opts.AuditOutputPaths) == 0 {
+		output, closeOutput, err := zap.Open(opts.outputPaths()...)
+		if err != nil {
+			closeErrorOutput()
+			return nil, fmt.Errorf("logger build: output: %w", err)
+		}
+		shared.output = output
+		closeShared = func() {
+			closeOutput()
+			closeErrorOutput()
+		}
+	}
+	// the audit logger is built first, so that atom is left unchanged if
+	// either fails
+	audit, _, _, closeAudit, err := build(opts.audit(), zap.NewAtomicLevel(), shared)
+	if err != nil {
+		closeShared()
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	l, zapConfig, loggerMode, closeGlobal, err := build(opts, atom, shared)
+	if err != nil {
+		closeAudit()
+		closeShared()
+		return nil, err
+	}
+	return &globalLoggers{
+		logger:      l,
+		audit:       audit.Named("audit"),
+		config:      zapConfig,
+		modes:       loggerMode,
+		errorOutput: errorOutput,
+		close: func() {
+			closeGlobal()
+			closeAudit()
+			closeShared()
+		},
+	}, nil
+}
+
+// build validates opts and builds the corresponding zap logger, whose level is
+// atom, writing to the shared outputs if any rather than opening the output
+// and error output paths. It returns the zap.Config used, the modes the logger
+// runs in and the function closing the outputs it opened, to call once the
+// logger is no longer used.
+func build(opts Options, atom zap.AtomicLevel, shared *sharedOutputs) (*zap.Logger, zap.Config, []string, func(), error) {
 	var (
 		zapConfig     zap.Config
 		encoderConfig zapcore.EncoderConfig
-		atom          zap.AtomicLevel
 		loggerMode    []string
+		// the level is only set on atom once the logger is built, as atom may
+		// be the level of the global logger, which Reconfigure must not change
+		// on error
+		level = zap.InfoLevel
 	)
 
-	correlationIdContextKey = "correlation_id"
-	correlationIdFieldKey = "correlation_id"
+	if err := opts.validate(); err != nil {
+		return nil, zapConfig, nil, nil, fmt.Errorf("logger config: %w", err)
+	}
 
-	if developmentMode {
+	if opts.DevelopmentMode {
 		loggerMode = append(loggerMode, "dev")
-		atom = zap.NewAtomicLevelAt(zap.DebugLevel)
+		level = zap.DebugLevel
 		encoderConfig = zapcore.EncoderConfig{
 			TimeKey:        "ts",
 			LevelKey:       "level",
@@ -131,9 +651,9 @@ func Init(ctx context.Context, enableLogLevelEndpoint, developmentMode bool) {
 			StacktraceKey:  "stacktrace",
 			LineEnding:     zapcore.DefaultLineEnding,
 			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeTime:     zapcore.RFC3339TimeEncoder,
+			EncodeTime:     opts.timeEncoder(),
 			EncodeDuration: zapcore.MillisDurationEncoder,
-			EncodeCaller:   zapcore.FullCallerEncoder,
+			EncodeCaller:   opts.callerEncoder(),
 		}
 		zapConfig = zap.Config{
 			Level:             atom,
@@ -141,15 +661,14 @@ func Init(ctx context.Context, enableLogLevelEndpoint, developmentMode bool) {
 			DisableCaller:     false,
 			DisableStacktrace: false,
 			Sampling:          nil,
-			Encoding:          "json",
+			Encoding:          opts.encoding(),
 			EncoderConfig:     encoderConfig,
-			OutputPaths:       []string{"stdout"},
-			ErrorOutputPaths:  []string{"stdout"},
-			InitialFields:     nil,
+			OutputPaths:       opts.outputPaths(),
+			ErrorOutputPaths:  opts.errorOutputPaths(),
+			InitialFields:     opts.initialFields(),
 		}
 	} else {
 		loggerMode = append(loggerMode, "prod")
-		atom = zap.NewAtomicLevelAt(zap.InfoLevel)
 		encoderConfig = zapcore.EncoderConfig{
 			TimeKey:        "ts",
 			LevelKey:       "level",
@@ -160,44 +679,183 @@ func Init(ctx context.Context, enableLogLevelEndpoint, developmentMode bool) {
 			StacktraceKey:  "stacktrace",
 			LineEnding:     zapcore.DefaultLineEnding,
 			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeTime:     zapcore.RFC3339TimeEncoder,
+			EncodeTime:     opts.timeEncoder(),
 			EncodeDuration: zapcore.MillisDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
+			EncodeCaller:   opts.callerEncoder(),
 		}
 		zapConfig = zap.Config{
 			Level:             atom,
 			Development:       false,
 			DisableCaller:     false,
 			DisableStacktrace: false,
-			Sampling:          &zap.SamplingConfig{Initial: 100, Thereafter: 100},
-			Encoding:          "json",
+			Sampling:          opts.sampling(),
+			Encoding:          opts.encoding(),
 			EncoderConfig:     encoderConfig,
-			OutputPaths:       []string{"stdout"},
-			ErrorOutputPaths:  []string{"stdout"},
-			InitialFields:     nil,
+			OutputPaths:       opts.outputPaths(),
+			ErrorOutputPaths:  opts.errorOutputPaths(),
+			InitialFields:     opts.initialFields(),
 		}
 	}
 
-	if enableLogLevelEndpoint {
-		loggerMode = append(loggerMode, "serveHttp")
-		go func() {
-			mux := http.NewServeMux()
-			mux.Handle("/loglevel", atom)
-			_ = http.ListenAndServe(":53835", mux)
-		}()
+	if opts.Level != nil {
+		level = *opts.Level
+	}
+	envLevel, envLevelErr := opts.envLevel()
+	if envLevel != nil {
+		level = *envLevel
 	}
 
-	l, err := zapConfig.Build()
-	if err != nil {
-		panic(fmt.Sprintf("logger initalization error: %s", err.Error()))
+	if zapConfig.Encoding == "console" {
+		zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		if opts.color() {
+			zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+	}
+	if opts.DatadogMode {
+		loggerMode = append(loggerMode, "datadog")
+		zapConfig.EncoderConfig.LevelKey = "status"
+		zapConfig.EncoderConfig.MessageKey = "message"
+		zapConfig.EncoderConfig.EncodeLevel = datadogLevelEncoder
+	}
+	if opts.GCPMode {
+		loggerMode = append(loggerMode, "gcp")
+		zapConfig.EncoderConfig.LevelKey = "severity"
+		zapConfig.EncoderConfig.MessageKey = "message"
+		zapConfig.EncoderConfig.EncodeLevel = gcpLevelEncoder
 	}
 
-	l.Info("Logger initialized successfully", zap.Strings("logger_modes", loggerMode))
-	if enableLogLevelEndpoint {
-		l.Info("Logger HTTP Server active on :53835/loglevel")
+	if opts.IncludeFunction {
+		zapConfig.EncoderConfig.FunctionKey = "func"
+	}
+	opts.FieldKeys.apply(&zapConfig.EncoderConfig)
+
+	// the outputs are opened here rather than by zap, which does not give
+	// their close functions back
+	var closers []func()
+	closeOutputs := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+	var buildOpts []zap.Option
+	outputPaths, errorOutputPaths, sampling := zapConfig.OutputPaths, zapConfig.ErrorOutputPaths, zapConfig.Sampling
+	var (
+		output, errorOutput zapcore.WriteSyncer
+		err                 error
+	)
+	if shared != nil {
+		output, errorOutput = shared.output, shared.errorOutput
+	}
+	if errorOutput == nil {
+		var closeErrorOutput func()
+		if errorOutput, closeErrorOutput, err = zap.Open(errorOutputPaths...); err != nil {
+			return nil, zapConfig, nil, nil, fmt.Errorf("logger build: error output: %w", err)
+		}
+		closers = append(closers, closeErrorOutput)
+	}
+	if output == nil {
+		var closeOutput func()
+		if output, closeOutput, err = zap.Open(outputPaths...); err != nil {
+			closeOutputs()
+			return nil, zapConfig, nil, nil, fmt.Errorf("logger build: output: %w", err)
+		}
+		closers = append(closers, closeOutput)
+	}
+	zapConfig.ErrorOutputPaths = nil
+	buildOpts = append(buildOpts, zap.ErrorOutput(errorOutput))
+	core, stopBuffering, err := newOutputCore(zapConfig, opts, output)
+	if err != nil {
+		closeOutputs()
+		return nil, zapConfig, nil, nil, fmt.Errorf("logger build: output: %w", err)
+	}
+	closers = append(closers, stopBuffering)
+	// this core replaces the one zap builds, which must not open the output
+	// paths a second time
+	zapConfig.OutputPaths = nil
+	buildOpts = append(buildOpts, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return core
+	}))
+	buildOpts = append(buildOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return levelOverrideCore{Core: c}
+	}))
+	if opts.DisableStacktrace {
+		zapConfig.DisableStacktrace = true
+	} else if opts.StacktraceLevel != nil {
+		// zap.Config only knows its own default levels
+		zapConfig.DisableStacktrace = true
+		buildOpts = append(buildOpts, zap.AddStacktrace(*opts.StacktraceLevel))
+	}
+	if opts.Syslog != nil {
+		core, closeCore, err := newSyslogTeeCore(*opts.Syslog, zapConfig, opts)
+		if err != nil {
+			closeOutputs()
+			return nil, zapConfig, nil, nil, fmt.Errorf("logger build: syslog: %w", err)
+		}
+		closers = append(closers, closeCore)
+		buildOpts = append(buildOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, core)
+		}))
+	}
+	if len(opts.HighSeverityOutputPaths) > 0 {
+		core, closeCore, err := newHighSeverityTeeCore(opts.HighSeverityOutputPaths, opts.highSeverityLevel(), zapConfig, opts)
+		if err != nil {
+			closeOutputs()
+			return nil, zapConfig, nil, nil, fmt.Errorf("logger build: high severity output: %w", err)
+		}
+		closers = append(closers, closeCore)
+		buildOpts = append(buildOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, core)
+		}))
+	}
+	if opts.HTTPSink != nil {
+		core, closeCore, err := newHTTPTeeCore(*opts.HTTPSink, zapConfig, opts)
+		if err != nil {
+			closeOutputs()
+			return nil, zapConfig, nil, nil, fmt.Errorf("logger build: http sink: %w", err)
+		}
+		closers = append(closers, closeCore)
+		buildOpts = append(buildOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, core)
+		}))
+	}
+	if sampling != nil {
+		// one sampler for all the outputs, which zap would only install on
+		// its own core, sampling every level
+		tick := opts.samplingTick()
+		buildOpts = append(buildOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return newSampler(c, sampling, tick)
+		}))
 	}
+	if opts.Buffering != nil {
+		loggerMode = append(loggerMode, "buffered")
+	}
+	if opts.RateLimit != nil {
+		rl := *opts.RateLimit
+		var stopRateLimit func()
+		buildOpts = append(buildOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			c, stopRateLimit = newRateLimitCore(c, rl)
+			return c
+		}))
+		closers = append(closers, func() {
+			if stopRateLimit != nil {
+				stopRateLimit()
+			}
+		})
+	}
+	buildOpts = append(buildOpts, zap.Hooks(runLevelHooks), zap.WithFatalHook(fatalHook{}))
 
-	logger = &CLogger{*l}
+	zapConfig.Sampling = nil
+	l, err := zapConfig.Build(buildOpts...)
+	zapConfig.OutputPaths, zapConfig.ErrorOutputPaths, zapConfig.Sampling = outputPaths, errorOutputPaths, sampling
+	if err != nil {
+		closeOutputs()
+		return nil, zapConfig, nil, nil, fmt.Errorf("logger build: %w", err)
+	}
+	atom.SetLevel(level)
+	if envLevelErr != nil {
+		l.Warn("Logger ignored the level set in the environment", zap.Error(envLevelErr))
+	}
+	return l, zapConfig, loggerMode, closeOutputs, nil
 }
 
 func (l *CSugaredLogger) Print(args ...interface{}) {
@@ -212,6 +870,30 @@ func (l *CSugaredLogger) Printf(format string, args ...interface{}) {
 	l.Debugf(format, args...)
 }
 
+func (l *CSugaredLogger) Printfln(format string, args ...interface{}) {
+	l.Debugf(format, args...)
+}
+
 func (l *CSugaredLogger) Fatalln(args ...interface{}) {
-	l.Fatal(args)
+	l.Fatal(args...)
+}
+
+func (l *CSugaredLogger) Panicln(args ...interface{}) {
+	l.Panic(args...)
+}
+
+func (l *CSugaredLogger) Errorln(args ...interface{}) {
+	l.Error(args...)
+}
+
+// DPanicf logs a formatted message at DPanic level. Like DPanic, which CLogger
+// gets from zap.Logger, it panics after logging in development mode, i.e. when
+// DevelopmentMode was set at Init, and only logs otherwise. Use them for
+// programmer errors which should be caught loudly during development but must
+// not bring production down. CSugaredLogger has both from zap.SugaredLogger.
+func (l *CLogger) DPanicf(format string, args ...interface{}) {
+	if !l.Core().Enabled(zap.DPanicLevel) {
+		return
+	}
+	l.Logger.WithOptions(zap.AddCallerSkip(1)).DPanic(fmt.Sprintf(format, args...))
 }
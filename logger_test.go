@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLConcurrent(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	var wg sync.WaitGroup
+	loggers := make([]*CLogger, 8)
+	for i := range loggers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loggers[i] = L()
+		}(i)
+	}
+	wg.Wait()
+	for i, l := range loggers {
+		if l != Logger() {
+			t.Errorf("L() in goroutine %d = %p, want the global logger %p", i, l, Logger())
+		}
+	}
+}
+
+func TestFatalln(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := &CLogger{Logger: *zap.New(core)}
+	// zap does not allow WriteThenNoop as the fatal hook
+	s := l.WithOptions(zap.WithFatalHook(zapcore.WriteThenPanic)).Sugar()
+	fatal := func(log func(...interface{})) {
+		defer func() { _ = recover() }()
+		log("foo", 42)
+	}
+	fatal(s.Fatalln)
+	fatal(s.Fatal)
+	entries := logs.FilterLevelExact(zap.FatalLevel).All()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d fatal entries, want 2", len(entries))
+	}
+	if got, want := entries[0].Message, entries[1].Message; got != want || strings.Contains(got, "[") {
+		t.Errorf("Fatalln message = %q, want %q as logged by Fatal", got, want)
+	}
+}
+
+func TestPrintfln(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	s := (&CLogger{Logger: *zap.New(core)}).Sugar()
+	s.Printfln("user %s", "alice")
+	s.Printf("user %s", "alice")
+	entries := logs.FilterLevelExact(zap.DebugLevel).All()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d debug entries, want 2", len(entries))
+	}
+	if got, want := entries[0].Message, entries[1].Message; got != want {
+		t.Errorf("Printfln message = %q, want %q as logged by Printf", got, want)
+	}
+}
+
+// costlyObject stands for a field which is costly to encode, such as a
+// request body.
+type costlyObject struct {
+	values []string
+}
+
+func (o costlyObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return enc.AddArray("values", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, v := range o.values {
+			arr.AppendString(v)
+		}
+		return nil
+	}))
+}
+
+// benchmarkLogger returns a logger discarding its output, at Info level.
+func benchmarkLogger() *CLogger {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(io.Discard), zap.InfoLevel)
+	return &CLogger{Logger: *zap.New(core)}
+}
+
+// BenchmarkWithLazy compares With and WithLazy on a logger at Info level which
+// only logs at Debug level with the field, so that it is never written.
+func BenchmarkWithLazy(b *testing.B) {
+	l := benchmarkLogger()
+	field := zap.Object("request", costlyObject{values: strings.Fields(strings.Repeat("value ", 100))})
+	b.Run("With", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.With(field).Debug("Request")
+		}
+	})
+	b.Run("WithLazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.WithLazy(field).Debug("Request")
+		}
+	})
+}
+
+func TestWithCorrelationIdDoesNotAllocate(t *testing.T) {
+	l := benchmarkLogger()
+	ctx := context.Background()
+	for name, with := range map[string]func(){
+		"nil":           func() { l.WithCorrelationId(nil) },
+		"empty":         func() { l.WithCorrelationId("") },
+		"empty context": func() { l.WithContextCorrelationId(ctx) },
+	} {
+		if allocs := testing.AllocsPerRun(100, with); allocs != 0 {
+			t.Errorf("%s: %v allocations, want 0", name, allocs)
+		}
+	}
+}
+
+func BenchmarkWithCorrelationId(b *testing.B) {
+	l := benchmarkLogger()
+	ctx := context.Background()
+	for _, bb := range []struct {
+		name string
+		with func()
+	}{
+		{"nil", func() { l.WithCorrelationId(nil) }},
+		{"empty", func() { l.WithCorrelationId("") }},
+		{"empty context", func() { l.WithContextCorrelationId(ctx) }},
+		{"id", func() { l.WithCorrelationId("b7f1c3a2-5d4e-4f60-9a1b-2c3d4e5f6a7b") }},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bb.with()
+			}
+		})
+	}
+}
+
+func TestSugaredWithInfow(t *testing.T) {
+	out := newMemorySink(t, "sugared-with")
+	l, err := New(Options{DevelopmentMode: true, Encoding: "json", OutputPaths: []string{"memory://sugared-with"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := l.Sugar().WithContextCorrelationId(ContextWithCorrelationId(context.Background(), "42")).With("order_id", 7)
+	log.Infow("Order shipped", "carrier", "ups")
+	log.With("dangling").Infow("Odd with", "odd")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("logged %d lines, want 4: %q", len(lines), lines)
+	}
+	for _, want := range []string{`"correlation_id":"42"`, `"order_id":7`, `"carrier":"ups"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("entry = %s, want %s", lines[0], want)
+		}
+	}
+	for i, ignored := range map[int]string{1: "dangling", 2: "odd"} {
+		if !strings.Contains(lines[i], `"level":"error"`) || !strings.Contains(lines[i], `"ignored":"`+ignored+`"`) {
+			t.Errorf("entry = %s, want an error about %q", lines[i], ignored)
+		}
+	}
+	if !strings.Contains(lines[3], `"msg":"Odd with"`) || !strings.Contains(lines[3], `"correlation_id":"42"`) {
+		t.Errorf("entry = %s, want the entry logged with its correlation ID", lines[3])
+	}
+}
@@ -0,0 +1,46 @@
+// Package loggergrpc provides gRPC interceptors propagating the correlation ID
+// of github.com/danbordeanu/go-logger. It is a module of its own, so that
+// applications which do not use gRPC do not depend on it.
+package loggergrpc
+
+import (
+	"context"
+
+	logger "github.com/danbordeanu/go-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor returns a gRPC server interceptor which reads the
+// correlation ID from the incoming metadata, generates a new one if absent and
+// stores it in the context under the correlation ID context key. The metadata
+// key is the correlation ID header (see logger.SetCorrelationIdHeader), lowercased as
+// gRPC requires. The ID is also sent back in the response header metadata.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var correlationId string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(logger.CorrelationIdHeader()); len(values) > 0 {
+				correlationId = values[0]
+			}
+		}
+		if correlationId == "" {
+			correlationId = logger.NewCorrelationId()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(logger.CorrelationIdHeader(), correlationId))
+		ctx = logger.ContextWithCorrelationId(ctx, correlationId)
+		return handler(ctx, req)
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC client interceptor which forwards the
+// correlation ID found in the context, if any, in the outgoing metadata so that
+// the server side can pick it up with UnaryServerInterceptor.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if correlationId, ok := logger.CorrelationIdFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, logger.CorrelationIdHeader(), correlationId)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
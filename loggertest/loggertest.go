@@ -0,0 +1,64 @@
+// Package loggertest provides loggers and assertions for unit tests of code
+// using the logger package, as zaptest does for zap. It is kept out of the
+// logger package so that production binaries do not import testing.
+package loggertest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	logger "github.com/danbordeanu/go-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// NewTest returns a logger for unit tests which records every entry, at any
+// level, in the returned ObservedLogs so tests can assert on them. Entries are
+// also written to the test log via tb, so they show up when a test fails. It
+// does not touch the global logger.
+func NewTest(tb testing.TB) (*logger.CLogger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := zaptest.NewLogger(tb, zaptest.Level(zapcore.DebugLevel))
+	return &logger.CLogger{Logger: *zap.New(zapcore.NewTee(core, l.Core()))}, logs
+}
+
+// AssertLogged fails the test unless logs holds an entry at level whose
+// message contains msgSubstring, and returns the first such entry so that its
+// fields can be checked with AssertField:
+//
+//	l, logs := loggertest.NewTest(t)
+//	handler(l)
+//	entry := loggertest.AssertLogged(t, logs, zap.ErrorLevel, "panic")
+//	loggertest.AssertField(t, entry, "op", "panic_logger")
+func AssertLogged(tb testing.TB, logs *observer.ObservedLogs, level zapcore.Level, msgSubstring string) observer.LoggedEntry {
+	tb.Helper()
+	for _, e := range logs.All() {
+		if e.Level == level && strings.Contains(e.Message, msgSubstring) {
+			return e
+		}
+	}
+	tb.Errorf("no %s entry logged with a message containing %q", level, msgSubstring)
+	return observer.LoggedEntry{}
+}
+
+// AssertField fails the test unless entry has a field key equal to value.
+// Values are compared as encoded, so integers match regardless of their type
+// and errors match their message.
+func AssertField(tb testing.TB, entry observer.LoggedEntry, key string, value interface{}) {
+	tb.Helper()
+	got, ok := entry.ContextMap()[key]
+	if !ok {
+		tb.Errorf("entry %q has no field %q", entry.Message, key)
+		return
+	}
+	if err, isErr := value.(error); isErr {
+		value = err.Error()
+	}
+	if !reflect.DeepEqual(got, value) && fmt.Sprint(got) != fmt.Sprint(value) {
+		tb.Errorf("entry %q has field %q = %v, want %v", entry.Message, key, got, value)
+	}
+}
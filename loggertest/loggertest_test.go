@@ -0,0 +1,16 @@
+package loggertest
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNewTest(t *testing.T) {
+	l, logs := NewTest(t)
+	l.Debug("Request failed", zap.Int("status", 500), zap.Error(errors.New("timeout")))
+	entry := AssertLogged(t, logs, zap.DebugLevel, "failed")
+	AssertField(t, entry, "status", 500)
+	AssertField(t, entry, "error", errors.New("timeout"))
+}
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var correlationIdHeader = "X-Correlation-ID"
+
+// SetCorrelationIdHeader sets the HTTP header CorrelationMiddleware reads and
+// writes the correlation ID from and to. By default, it is "X-Correlation-ID"
+func SetCorrelationIdHeader(header string) {
+	if header == "" {
+		return
+	}
+	correlationIdHeader = header
+}
+
+// CorrelationIdHeader returns the HTTP header the correlation ID is read from
+// and written to, as set with SetCorrelationIdHeader.
+func CorrelationIdHeader() string {
+	return correlationIdHeader
+}
+
+// CorrelationMiddleware is a net/http middleware which reads the correlation ID
+// from the request header (see SetCorrelationIdHeader), generates a new one if
+// absent, stores it in the request context with ContextWithCorrelationId and
+// echoes it back in the response header. Handlers can then use
+// WithContextCorrelationId(r.Context()) to log with it.
+func CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWithCorrelationId(w, r, next, r.Header.Get(correlationIdHeader))
+	})
+}
+
+// CorrelationMiddlewareWithHeaders is like CorrelationMiddleware, but reads the
+// correlation ID from the first of headers which is set, e.g. for clients
+// sending either "X-Request-ID" or "X-Correlation-ID". The ID is echoed back
+// in the header set with SetCorrelationIdHeader.
+func CorrelationMiddlewareWithHeaders(headers []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var correlationId string
+		for _, h := range headers {
+			if correlationId = r.Header.Get(h); correlationId != "" {
+				break
+			}
+		}
+		serveWithCorrelationId(w, r, next, correlationId)
+	})
+}
+
+// serveWithCorrelationId serves r with next, with correlationId, or a new one
+// if empty, stored in the request context and echoed back in the response
+// header.
+func serveWithCorrelationId(w http.ResponseWriter, r *http.Request, next http.Handler, correlationId string) {
+	if correlationId == "" {
+		correlationId = NewCorrelationId()
+	}
+	w.Header().Set(correlationIdHeader, correlationId)
+	ctx := ContextWithCorrelationId(r.Context(), correlationId)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// WithRequest returns an instance of the same logger with the method and path
+// of r, and its correlation ID, added to it. The correlation ID is taken from
+// the request context if CorrelationMiddleware stored one, else from the
+// header set with SetCorrelationIdHeader. It is a lighter alternative to the
+// middleware for simple handlers.
+func (l *CLogger) WithRequest(r *http.Request) *CLogger {
+	return l.With(zap.String("method", r.Method), zap.String("path", r.URL.Path)).
+		WithCorrelationId(requestCorrelationId(r, l.keys()))
+}
+
+// WithRequest returns an instance of the same logger with the method and path
+// of r, and its correlation ID, added to it, like CLogger.WithRequest.
+func (l *CSugaredLogger) WithRequest(r *http.Request) *CSugaredLogger {
+	return l.With(zap.String("method", r.Method), zap.String("path", r.URL.Path)).
+		WithCorrelationId(requestCorrelationId(r, l.keys()))
+}
+
+// requestCorrelationId returns the correlation ID of r, from its context or
+// its header, or nil if it has none.
+func requestCorrelationId(r *http.Request, keys *correlationIdKeys) interface{} {
+	if correlationId := contextCorrelationId(r.Context(), keys.contextKey); correlationId != nil {
+		return correlationId
+	}
+	return r.Header.Get(correlationIdHeader)
+}
+
+// AccessLogMiddleware is a net/http middleware which logs every request with
+// its method, path, status code, duration and the number of bytes written,
+// using the global logger decorated with the correlation ID of the request
+// context. Put it inside CorrelationMiddleware to get the correlation ID.
+//
+// Requests are logged at Info level, or at Warn and Error level for 4xx and 5xx
+// status codes respectively.
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+
+		level := zapcore.InfoLevel
+		switch {
+		case rw.statusCode() >= http.StatusInternalServerError:
+			level = zapcore.ErrorLevel
+		case rw.statusCode() >= http.StatusBadRequest:
+			level = zapcore.WarnLevel
+		}
+		log := Logger().WithContextCorrelationId(r.Context())
+		if ce := log.Check(level, "HTTP request"); ce != nil {
+			ce.Write(
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", rw.statusCode()),
+				zap.Duration("duration", time.Since(start)),
+				zap.Int("bytes", rw.bytes),
+			)
+		}
+	})
+}
+
+// RecoveryMiddleware is a net/http middleware which recovers from panics in the
+// handlers, logs them with their stack trace at Error level on the global
+// logger decorated with the correlation ID of the request context and the field
+// "op" set to "http_panic", and responds with a 500 Internal Server Error. Put
+// it inside CorrelationMiddleware to get the correlation ID.
+//
+// As with net/http, a panic with http.ErrAbortHandler aborts the response
+// silently.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			log := SugaredLogger().WithContextCorrelationId(r.Context()).With("op", "http_panic")
+			log.Errorf("panic: %s stack: %s", rec, string(debug.Stack()))
+			w.WriteHeader(http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// responseWriter records the status code and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush implements http.Flusher, for streaming responses such as server-sent
+// events, when the underlying ResponseWriter does. It does nothing otherwise.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, for protocol upgrades such as websockets,
+// when the underlying ResponseWriter does. It fails with
+// http.ErrNotSupported otherwise.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns the status code sent, which is 200 when the handler did
+// not write anything.
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogMiddlewareFlushHijack(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	out := newMemorySink(t, "access-log")
+	if err := InitWithOptionsE(context.Background(), Options{Quiet: true, OutputPaths: []string{"memory://access-log"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("flush", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f, ok := w.(http.Flusher)
+			if !ok {
+				t.Fatal("the response writer is not a http.Flusher")
+			}
+			_, _ = w.Write([]byte("data: event\n\n"))
+			f.Flush()
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+		if !rec.Flushed {
+			t.Error("the response was not flushed")
+		}
+	})
+
+	t.Run("hijack", func(t *testing.T) {
+		logged := make(chan struct{})
+		handler := AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h, ok := w.(http.Hijacker)
+			if !ok {
+				t.Error("the response writer is not a http.Hijacker")
+				return
+			}
+			conn, rw, err := h.Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+			_ = rw.Flush()
+		}))
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.ServeHTTP(w, r)
+			close(logged)
+		}))
+		defer srv.Close()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "test")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Errorf("status = %d, want 101", resp.StatusCode)
+		}
+		<-logged
+	})
+
+	if got := out.String(); !strings.Contains(got, `"status":101`) {
+		t.Errorf("access log = %q, want the upgrade logged with status 101", got)
+	}
+}
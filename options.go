@@ -0,0 +1,458 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/term"
+)
+
+// Options holds the configuration accepted by InitWithOptions. The zero value
+// is a production logger writing to stdout.
+type Options struct {
+	// EnableLogLevelEndpoint exposes an HTTP endpoint which can be used to
+	// change the log level dynamically.
+	EnableLogLevelEndpoint bool
+	// DisableShutdownLog disables the "Logger shutting down" entry, with the
+	// uptime, which is logged when the context passed to Init is done.
+	DisableShutdownLog bool
+	// PanicDedupWindow, when set, makes RecoverLogger and RecoverToError log
+	// a panic once per window: identical panics within the window are only
+	// counted, and reported in a summary when it ends, so that a panic storm
+	// does not flood the logs.
+	PanicDedupWindow time.Duration
+	// Quiet disables the "Logger initialized successfully" and "Logger HTTP
+	// Server active" entries logged by Init, so that the first lines of the
+	// output are the application's own.
+	Quiet bool
+	// LogLevelEndpointAddr is the address the log level endpoint listens on,
+	// e.g. "127.0.0.1:9000" or ":0" for an ephemeral port. Defaults to
+	// ":53835". Use LogLevelEndpointAddr() to discover the bound address.
+	LogLevelEndpointAddr string
+	// DevelopmentMode sets the level to Debug and makes caller fields more
+	// explicit. Do not enable this in production.
+	DevelopmentMode bool
+	// TimeEncoding selects the timestamp format: "rfc3339" (the default),
+	// "rfc3339nano", "iso8601", or the floating point "epoch" seconds,
+	// "epoch_millis" and integer "epoch_nanos" since the Unix epoch.
+	TimeEncoding string
+	// CallerEncoding selects the format of the caller: "short" for the last
+	// directory and the file name, e.g. "logger/logger.go:42", or "full" for
+	// the full path. Defaults to "full" in development mode and "short"
+	// otherwise.
+	CallerEncoding string
+	// CallerTrimPrefix is stripped from full callers, e.g. the directory of
+	// the module, to keep them readable.
+	CallerTrimPrefix string
+	// Level, when set, is the initial log level. It overrides the default of
+	// Debug in development mode and Info otherwise. The log level endpoint can
+	// still change it afterwards. See also WithLevel.
+	Level *zapcore.Level
+	// LevelEnv is the environment variable which, when set, holds the
+	// initial log level, e.g. "debug", taking precedence over Level so that
+	// each deployment can pick its verbosity. Defaults to "LOG_LEVEL"; set it
+	// to "-" to ignore the environment. An invalid level is logged at Warn
+	// level and ignored.
+	LevelEnv string
+	// DisableStacktrace stops stacktraces from being captured at all.
+	DisableStacktrace bool
+	// StacktraceLevel, when set, is the minimum level at which stacktraces are
+	// captured, e.g. zapcore.FatalLevel to keep them out of Error logs. It
+	// defaults to Warn in development mode and to Error otherwise.
+	StacktraceLevel *zapcore.Level
+	// DatadogMode makes the output natively understood by Datadog: the level
+	// is written as "status" with Datadog's values, the message as "message",
+	// and WithContextTrace writes "dd.trace_id" and "dd.span_id" in Datadog's
+	// decimal format. The correlation ID field is not affected.
+	DatadogMode bool
+	// GCPMode makes the output natively understood by Google Cloud Logging:
+	// the level is written as "severity" with Cloud Logging's values, the
+	// message as "message", and WithContextTrace writes the trace and span IDs
+	// in the "logging.googleapis.com/trace" and "logging.googleapis.com/spanId"
+	// fields. It cannot be combined with DatadogMode.
+	GCPMode bool
+	// GCPProjectID is the Google Cloud project ID used to format trace IDs as
+	// "projects/GCPProjectID/traces/TRACE_ID" in GCPMode, which links the logs
+	// to Cloud Trace.
+	GCPProjectID string
+	// CorrelationIdContextKey is the string context key under which the
+	// logger also looks for a correlation ID, for contexts filled by the
+	// application itself rather than with ContextWithCorrelationId, and
+	// CorrelationIdFieldKey the key of the correlation ID in log entries. Both
+	// default to "correlation_id".
+	CorrelationIdContextKey string
+	CorrelationIdFieldKey   string
+	// SeverityNumber adds the syslog severity of the level of every entry,
+	// from 7 for Debug to 2 for Panic and Fatal, in the "severity_num" field,
+	// for ingestion systems which sort by numeric severity.
+	SeverityNumber bool
+	// Environment, when set, is the deployment environment, e.g. "staging",
+	// added in the "env" field of every entry. See also Environment().
+	Environment string
+	// IncludeHostInfo adds the host name and the process ID in the "host"
+	// and "pid" fields of every entry, to tell instances apart.
+	IncludeHostInfo bool
+	// IncludeFunction adds the function which logged in the "func" field of
+	// every entry in production mode too, like development mode does.
+	IncludeFunction bool
+	// IncludeGoroutineId adds the ID of the goroutine which logged in the
+	// "goroutine" field of every entry, to help debugging concurrency issues.
+	// Reading it costs a stack trace per entry, so keep it for development.
+	IncludeGoroutineId bool
+	// IncludePackage adds the import path of the package which logged in the
+	// "package" field of every entry, to group entries by package without
+	// parsing the caller. It is looked up once per call site.
+	IncludePackage bool
+	// FieldKeys overrides the keys of the fields every entry has, e.g. to
+	// write "message" instead of "msg". It takes precedence over DatadogMode
+	// and GCPMode.
+	FieldKeys FieldKeys
+	// Encoding is "json", "console" or "logfmt". Defaults to "console" in
+	// development mode and to "json" otherwise. Set it to "json" to keep
+	// machine-readable logs in development mode, e.g. in CI.
+	Encoding string
+	// Color forces color-coded levels with the "console" encoding on (true)
+	// or off (false). By default levels are colored only when every output is
+	// stdout or stderr attached to a terminal, so that captured logs do not
+	// hold ANSI escape codes.
+	Color *bool
+	// Encoder, when set, builds the encoder of every output instead of the
+	// one selected by Encoding, e.g. for a proprietary format.
+	Encoder EncoderFunc
+	// DisableSampling turns off log sampling in production mode, so every
+	// message is emitted. Development mode never samples.
+	DisableSampling bool
+	// SamplingInitial and SamplingThereafter tune sampling in production mode:
+	// per tick, the first SamplingInitial entries with the same level and
+	// message are logged, then every SamplingThereafter-th one. Both default to
+	// 100. Entries at Warn level and above are never sampled.
+	SamplingInitial    int
+	SamplingThereafter int
+	// SamplingTick is the period over which sampling counts entries, e.g.
+	// 10 seconds to deduplicate harder during sustained error storms.
+	// Defaults to one second.
+	SamplingTick time.Duration
+	// Buffering, when set, buffers the writes to the output paths. Writes are
+	// synchronous by default.
+	Buffering *Buffering
+	// RateLimit, when set, caps how often identical messages are logged.
+	RateLimit *RateLimit
+	// OutputPaths is a list of URLs or file paths to write logging output to.
+	// See zap.Config for details, and RegisterSink for custom URL schemes.
+	// Defaults to "stdout".
+	OutputPaths []string
+	// RotatingFile, when set, additionally writes the logs to a file rotated
+	// by lumberjack, next to OutputPaths (stdout by default).
+	RotatingFile *RotatingFile
+	// Syslog, when set, additionally delivers the logs to a syslog daemon.
+	Syslog *Syslog
+	// HTTPSink, when set, ships the logs to an HTTP collector too.
+	HTTPSink *HTTPSink
+	// AuditOutputPaths is a list of URLs or file paths the entries of
+	// AuditLogger are written to, e.g. a file collected by a compliance
+	// pipeline. Defaults to OutputPaths, or stdout.
+	AuditOutputPaths []string
+	// InitialFields are added to every log entry of every logger, e.g. the
+	// service name, version and environment.
+	InitialFields map[string]interface{}
+	// HighSeverityOutputPaths is a list of URLs or file paths to which the
+	// entries at or above HighSeverityLevel are duplicated, e.g. an alerting
+	// sink, in addition to the regular outputs.
+	HighSeverityOutputPaths []string
+	// HighSeverityLevel is the minimum level of the entries written to
+	// HighSeverityOutputPaths. Defaults to Error.
+	HighSeverityLevel *zapcore.Level
+	// ErrorOutputPaths is a list of URLs or file paths to write internal
+	// logger errors to, such as failures to write to an output. It does not
+	// receive the Error level logs of the application: use
+	// HighSeverityOutputPaths for that. Defaults to "stdout".
+	ErrorOutputPaths []string
+}
+
+// timeEncoders maps the supported Options.TimeEncoding values to encoders.
+var timeEncoders = map[string]zapcore.TimeEncoder{
+	"":             zapcore.RFC3339TimeEncoder,
+	"rfc3339":      zapcore.RFC3339TimeEncoder,
+	"rfc3339nano":  zapcore.RFC3339NanoTimeEncoder,
+	"iso8601":      zapcore.ISO8601TimeEncoder,
+	"epoch":        zapcore.EpochTimeEncoder,
+	"epoch_millis": zapcore.EpochMillisTimeEncoder,
+	"epoch_nanos":  zapcore.EpochNanosTimeEncoder,
+}
+
+// WithLevel returns a copy of the options with the initial log level set to
+// level.
+func (o Options) WithLevel(level zapcore.Level) Options {
+	o.Level = &level
+	return o
+}
+
+// validate checks the options for values zap would reject or misinterpret.
+func (o Options) validate() error {
+	if o.DatadogMode && o.GCPMode {
+		return fmt.Errorf("DatadogMode and GCPMode are mutually exclusive")
+	}
+	if o.RateLimit != nil && o.RateLimit.PerSecond <= 0 {
+		return fmt.Errorf("rate limit must be positive, got %v per second", o.RateLimit.PerSecond)
+	}
+	if o.RotatingFile != nil && rotatingFileErr != nil {
+		return fmt.Errorf("rotating file: %w", rotatingFileErr)
+	}
+	if o.HTTPSink != nil && strings.TrimSpace(o.HTTPSink.URL) == "" {
+		return fmt.Errorf("empty http sink URL")
+	}
+	if o.SamplingInitial < 0 || o.SamplingThereafter < 0 {
+		return fmt.Errorf("negative sampling values %d/%d", o.SamplingInitial, o.SamplingThereafter)
+	}
+	if _, ok := timeEncoders[o.TimeEncoding]; !ok {
+		return fmt.Errorf("unknown time encoding %q", o.TimeEncoding)
+	}
+	switch o.CallerEncoding {
+	case "", "short", "full":
+	default:
+		return fmt.Errorf("unknown caller encoding %q", o.CallerEncoding)
+	}
+	switch o.Encoding {
+	case "", "json", "console", "logfmt":
+	default:
+		return fmt.Errorf("unknown encoding %q", o.Encoding)
+	}
+	for _, p := range o.OutputPaths {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty output path in %q", o.OutputPaths)
+		}
+	}
+	if o.RotatingFile != nil && strings.TrimSpace(o.RotatingFile.Filename) == "" {
+		return fmt.Errorf("empty rotating file name")
+	}
+	for _, p := range o.HighSeverityOutputPaths {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty high severity output path in %q", o.HighSeverityOutputPaths)
+		}
+	}
+	for _, p := range o.ErrorOutputPaths {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty error output path in %q", o.ErrorOutputPaths)
+		}
+	}
+	return nil
+}
+
+// sampling returns the sampling configuration for production mode, or nil if
+// sampling is disabled.
+func (o Options) sampling() *zap.SamplingConfig {
+	if o.DisableSampling {
+		return nil
+	}
+	sampling := &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+	if o.SamplingInitial > 0 {
+		sampling.Initial = o.SamplingInitial
+	}
+	if o.SamplingThereafter > 0 {
+		sampling.Thereafter = o.SamplingThereafter
+	}
+	return sampling
+}
+
+// highSeverityLevel returns the configured minimum level of the high severity
+// outputs or the Error default.
+func (o Options) highSeverityLevel() zapcore.Level {
+	if o.HighSeverityLevel == nil {
+		return zapcore.ErrorLevel
+	}
+	return *o.HighSeverityLevel
+}
+
+// timeEncoder returns the encoder matching the configured time encoding.
+func (o Options) timeEncoder() zapcore.TimeEncoder {
+	return timeEncoders[o.TimeEncoding]
+}
+
+// callerEncoder returns the encoder matching the configured caller encoding,
+// or the default of the mode.
+func (o Options) callerEncoder() zapcore.CallerEncoder {
+	full := o.CallerEncoding == "full" || o.CallerEncoding == "" && o.DevelopmentMode
+	if !full {
+		return zapcore.ShortCallerEncoder
+	}
+	if o.CallerTrimPrefix == "" {
+		return zapcore.FullCallerEncoder
+	}
+	prefix := o.CallerTrimPrefix
+	return func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+		if !caller.Defined {
+			enc.AppendString("undefined")
+			return
+		}
+		enc.AppendString(strings.TrimPrefix(caller.FullPath(), prefix))
+	}
+}
+
+// outputPaths returns the configured output paths or the stdout default, plus
+// the rotating file if any.
+func (o Options) outputPaths() []string {
+	paths := []string{"stdout"}
+	if len(o.OutputPaths) > 0 {
+		paths = append([]string(nil), o.OutputPaths...)
+	}
+	if o.RotatingFile != nil {
+		paths = append(paths, o.RotatingFile.URL())
+	}
+	return paths
+}
+
+// color reports whether the "console" encoding colors levels: as set in
+// Color, or when all outputs are terminals.
+func (o Options) color() bool {
+	if o.Color != nil {
+		return *o.Color
+	}
+	for _, path := range o.outputPaths() {
+		switch path {
+		case "stdout":
+			if !term.IsTerminal(int(os.Stdout.Fd())) {
+				return false
+			}
+		case "stderr":
+			if !term.IsTerminal(int(os.Stderr.Fd())) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// envLevel returns the level set in the LevelEnv environment variable, if
+// any, or an error if it is invalid.
+func (o Options) envLevel() (*zapcore.Level, error) {
+	name := o.LevelEnv
+	if name == "-" {
+		return nil, nil
+	}
+	if name == "" {
+		name = "LOG_LEVEL"
+	}
+	text, ok := os.LookupEnv(name)
+	if !ok || text == "" {
+		return nil, nil
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &level, nil
+}
+
+// initialFields returns the configured initial fields, with the environment
+// and host info fields if enabled.
+func (o Options) initialFields() map[string]interface{} {
+	if !o.IncludeHostInfo && o.Environment == "" {
+		return o.InitialFields
+	}
+	fields := make(map[string]interface{}, len(o.InitialFields)+3)
+	for k, v := range o.InitialFields {
+		fields[k] = v
+	}
+	if o.Environment != "" {
+		fields["env"] = o.Environment
+	}
+	if o.IncludeHostInfo {
+		if host, err := os.Hostname(); err == nil {
+			fields["host"] = host
+		}
+		fields["pid"] = os.Getpid()
+	}
+	return fields
+}
+
+// samplingTick returns the configured sampling tick or its default.
+func (o Options) samplingTick() time.Duration {
+	if o.SamplingTick <= 0 {
+		return time.Second
+	}
+	return o.SamplingTick
+}
+
+// correlationKeys returns the configured correlation ID keys or their
+// defaults.
+func (o Options) correlationKeys() *correlationIdKeys {
+	keys := &correlationIdKeys{contextKey: "correlation_id", fieldKey: "correlation_id"}
+	if o.CorrelationIdContextKey != "" {
+		keys.contextKey = o.CorrelationIdContextKey
+	}
+	if o.CorrelationIdFieldKey != "" {
+		keys.fieldKey = o.CorrelationIdFieldKey
+	}
+	return keys
+}
+
+// errorOutputPaths returns the configured error output paths or the stdout
+// default.
+func (o Options) errorOutputPaths() []string {
+	if len(o.ErrorOutputPaths) == 0 {
+		return []string{"stdout"}
+	}
+	return o.ErrorOutputPaths
+}
+
+// logLevelEndpointAddr returns the configured log level endpoint address or the
+// default one.
+func (o Options) logLevelEndpointAddr() string {
+	if o.LogLevelEndpointAddr == "" {
+		return defaultLogLevelEndpointAddr
+	}
+	return o.LogLevelEndpointAddr
+}
+
+// encoding returns the zap name of the configured encoding or of the default
+// one for the mode.
+func (o Options) encoding() string {
+	if o.Encoding == "logfmt" {
+		return LogfmtEncoding
+	}
+	if o.Encoding != "" {
+		return o.Encoding
+	}
+	if o.DevelopmentMode {
+		return "console"
+	}
+	return "json"
+}
+
+// FieldKeys holds the keys of the fields every entry has. Empty keys keep
+// their default, e.g. "ts" for Time or "msg" for Message.
+type FieldKeys struct {
+	Time       string
+	Level      string
+	Name       string
+	Caller     string
+	Function   string
+	Message    string
+	Stacktrace string
+}
+
+// apply sets the non-empty keys of k in cfg.
+func (k FieldKeys) apply(cfg *zapcore.EncoderConfig) {
+	for _, key := range []struct {
+		dst *string
+		src string
+	}{
+		{&cfg.TimeKey, k.Time},
+		{&cfg.LevelKey, k.Level},
+		{&cfg.NameKey, k.Name},
+		{&cfg.CallerKey, k.Caller},
+		{&cfg.FunctionKey, k.Function},
+		{&cfg.MessageKey, k.Message},
+		{&cfg.StacktraceKey, k.Stacktrace},
+	} {
+		if key.src != "" {
+			*key.dst = key.src
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// packageEncoder is a zapcore.Encoder which adds the import path of the
+// package which logged an entry in the "package" field. Entries without a
+// caller get no "package" field.
+type packageEncoder struct {
+	zapcore.Encoder
+}
+
+func (e packageEncoder) Clone() zapcore.Encoder {
+	return packageEncoder{e.Encoder.Clone()}
+}
+
+func (e packageEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if pkg := callerPackage(ent.Caller.PC); pkg != "" {
+		fields = append(fields[:len(fields):len(fields)], zap.String("package", pkg))
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// callerPackages caches the package of the callers seen so far, by program
+// counter, as there are only so many places which log.
+var callerPackages sync.Map
+
+// callerPackage returns the import path of the package of the function at pc,
+// e.g. "github.com/org/app/store" for "github.com/org/app/store.(*DB).Get",
+// or "" if pc is unknown.
+func callerPackage(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if pkg, ok := callerPackages.Load(pc); ok {
+		return pkg.(string)
+	}
+	var pkg string
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name := fn.Name()
+		// the function name starts after the last slash, at the first dot
+		slash := strings.LastIndexByte(name, '/')
+		if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+			// dots of the last element of the path are escaped, e.g.
+			// "gopkg.in/natefinch/lumberjack%2ev2.(*Logger).Write"
+			pkg = strings.ReplaceAll(name[:slash+1+dot], "%2e", ".")
+		}
+	}
+	callerPackages.Store(pc, pkg)
+	return pkg
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func TestCallerPackage(t *testing.T) {
+	for _, tt := range []struct {
+		fn   interface{}
+		want string
+	}{
+		{TestCallerPackage, "github.com/danbordeanu/go-logger"},
+		{(*lumberjack.Logger).Write, "gopkg.in/natefinch/lumberjack.v2"},
+		{strings.Contains, "strings"},
+	} {
+		pc := reflect.ValueOf(tt.fn).Pointer()
+		if got := callerPackage(pc); got != tt.want {
+			t.Errorf("callerPackage(%s) = %q, want %q", runtime.FuncForPC(pc).Name(), got, tt.want)
+		}
+	}
+}
+
+func TestIncludePackage(t *testing.T) {
+	out := newMemorySink(t, "package")
+	l, err := New(Options{IncludePackage: true, OutputPaths: []string{"memory://package"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("Packaged")
+	if got := out.String(); !strings.Contains(got, `"package":"github.com/danbordeanu/go-logger"`) {
+		t.Errorf("output = %q, want the package of the test", got)
+	}
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Keys PrettyPrint recognizes, in the default, Datadog and GCP layouts.
+var (
+	prettyTimeKeys    = []string{"ts", "time", "timestamp"}
+	prettyLevelKeys   = []string{"level", "status", "severity"}
+	prettyMessageKeys = []string{"msg", "message"}
+)
+
+// prettyMessageWidth is the width messages are padded to, so that the fields
+// of consecutive lines start in the same column.
+const prettyMessageWidth = 40
+
+// PrettyPrint reads JSON log lines, as written by this package, from r and
+// writes them to w in a human-readable form: the time, the level, the logger
+// name, the caller and the message, followed by the other fields sorted by
+// key, and the stack trace on the next lines. Levels are colored when w is a
+// terminal. Lines which are not JSON objects are copied as is, so that it can
+// be fed the raw output of an application:
+//
+//	logger.PrettyPrint(os.Stdin, os.Stdout)
+//
+// It returns when r is exhausted, with the first read or write error.
+func PrettyPrint(r io.Reader, w io.Writer) error {
+	color := false
+	if f, ok := w.(*os.File); ok {
+		color = term.IsTerminal(int(f.Fd()))
+	}
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			prettyPrintLine(bw, line, color)
+		}
+		if errors.Is(err, io.EOF) {
+			return bw.Flush()
+		}
+		if err != nil {
+			_ = bw.Flush()
+			return err
+		}
+		if br.Buffered() == 0 {
+			// keep up with a live stream
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// prettyPrintLine writes the human-readable form of line to w. Write errors
+// are kept by w, and returned by its Flush.
+func prettyPrintLine(w *bufio.Writer, line []byte, color bool) {
+	trimmed := bytes.TrimSpace(line)
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var entry map[string]interface{}
+	if len(trimmed) == 0 || trimmed[0] != '{' || dec.Decode(&entry) != nil {
+		w.Write(line)
+		if line[len(line)-1] != '\n' {
+			w.WriteByte('\n')
+		}
+		return
+	}
+
+	if ts, ok := takeField(entry, prettyTimeKeys); ok {
+		w.WriteString(prettyTime(ts))
+		w.WriteByte(' ')
+	}
+	level, _ := takeField(entry, prettyLevelKeys)
+	w.WriteString(prettyLevel(fmt.Sprint(level), color))
+	if name, ok := takeField(entry, []string{"logger"}); ok {
+		w.WriteByte(' ')
+		w.WriteString(fmt.Sprint(name))
+	}
+	if caller, ok := takeField(entry, []string{"caller"}); ok {
+		w.WriteByte(' ')
+		w.WriteString(fmt.Sprint(caller))
+	}
+	msg, _ := takeField(entry, prettyMessageKeys)
+	stacktrace, hasStacktrace := takeField(entry, []string{"stacktrace"})
+	w.WriteByte(' ')
+	if len(entry) == 0 {
+		w.WriteString(fmt.Sprint(msg))
+	} else {
+		fmt.Fprintf(w, "%-*s", prettyMessageWidth, fmt.Sprint(msg))
+	}
+
+	keys := make([]string, 0, len(entry))
+	for k := range entry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.WriteByte(' ')
+		w.WriteString(k)
+		w.WriteByte('=')
+		w.WriteString(prettyValue(entry[k]))
+	}
+	w.WriteByte('\n')
+	if hasStacktrace {
+		for _, frame := range strings.Split(fmt.Sprint(stacktrace), "\n") {
+			w.WriteString("    ")
+			w.WriteString(frame)
+			w.WriteByte('\n')
+		}
+	}
+}
+
+// takeField removes the first of keys found in entry and returns its value.
+func takeField(entry map[string]interface{}, keys []string) (interface{}, bool) {
+	for _, k := range keys {
+		if v, ok := entry[k]; ok {
+			delete(entry, k)
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// prettyTime formats ts, which is a formatted time or a number of seconds,
+// milliseconds or nanoseconds since the epoch, as written by the "epoch",
+// "epoch_millis" and "epoch_nanos" time encodings. The unit is told by the
+// magnitude of the number, which holds for the dates from 1973 to 5138.
+func prettyTime(ts interface{}) string {
+	n, ok := ts.(json.Number)
+	if !ok {
+		return fmt.Sprint(ts)
+	}
+	var t time.Time
+	if nanos, err := n.Int64(); err == nil && (nanos >= 1e17 || nanos <= -1e17) {
+		// parsed as an integer, as a float64 cannot hold them exactly
+		t = time.Unix(0, nanos)
+	} else if f, err := n.Float64(); err == nil {
+		switch abs := math.Abs(f); {
+		case abs >= 1e17:
+			t = time.Unix(0, int64(f))
+		case abs >= 1e11:
+			t = time.Unix(0, int64(f*float64(time.Millisecond)))
+		default:
+			t = time.Unix(0, int64(f*float64(time.Second)))
+		}
+		// a float64 holding a time since the epoch is only precise to the
+		// microsecond
+		t = t.Round(time.Microsecond)
+	} else {
+		return fmt.Sprint(ts)
+	}
+	return t.Format("2006-01-02T15:04:05.000Z0700")
+}
+
+// prettyLevel returns level upper-cased and padded to the same width for all
+// levels, colored as by the "console" encoding if color is set.
+func prettyLevel(level string, color bool) string {
+	level = strings.ToUpper(level)
+	padded := fmt.Sprintf("%-5s", level)
+	if !color {
+		return padded
+	}
+	code := 0
+	switch level {
+	case "DEBUG":
+		code = 35
+	case "INFO":
+		code = 34
+	case "WARN", "WARNING":
+		code = 33
+	case "ERROR", "DPANIC", "PANIC", "FATAL", "CRITICAL", "ALERT", "EMERGENCY":
+		code = 31
+	default:
+		return padded
+	}
+	return "\x1b[" + strconv.Itoa(code) + "m" + padded + "\x1b[0m"
+}
+
+// prettyValue formats a field value: strings as is unless they hold spaces
+// or quotes, and anything else as JSON.
+func prettyValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
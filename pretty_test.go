@@ -0,0 +1,19 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPrettyTime(t *testing.T) {
+	want := time.Unix(1700000000, 123000000).Format("2006-01-02T15:04:05.000Z0700")
+	for _, ts := range []string{"1700000000.123", "1700000000123", "1700000000123.0", "1700000000123000000"} {
+		if got := prettyTime(json.Number(ts)); got != want {
+			t.Errorf("prettyTime(%s) = %s, want %s", ts, got, want)
+		}
+	}
+	if got := prettyTime("2023-11-14T22:13:20.123Z"); got != "2023-11-14T22:13:20.123Z" {
+		t.Errorf("prettyTime of a formatted time = %s, want it as is", got)
+	}
+}
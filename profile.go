@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// profileEnv is the environment variable InitProfile reads the profile name
+// from when none is given.
+const profileEnv = "LOG_PROFILE"
+
+var (
+	profilesMu sync.Mutex
+	profiles   = map[string]Options{}
+)
+
+// RegisterProfile registers opts under name, e.g. "local", "staging" or
+// "prod", for InitProfile to select. Registering a name again replaces its
+// options.
+func RegisterProfile(name string, opts Options) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[name] = opts
+}
+
+// InitProfile bootstraps the logger like InitWithOptionsE with the options
+// registered under name. An empty name is taken from the LOG_PROFILE
+// environment variable, so that the environment picks the profile:
+//
+//	logger.RegisterProfile("local", logger.Options{DevelopmentMode: true})
+//	logger.RegisterProfile("prod", logger.Options{DatadogMode: true})
+//	if err := logger.InitProfile(ctx, ""); err != nil {
+//		...
+//	}
+//
+// An unknown profile is a "config" error.
+func InitProfile(ctx context.Context, name string) error {
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	profilesMu.Lock()
+	opts, ok := profiles[name]
+	profilesMu.Unlock()
+	if !ok {
+		return fmt.Errorf("logger config: unknown profile %q", name)
+	}
+	return InitWithOptionsE(ctx, opts)
+}
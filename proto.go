@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// protoMaxSize is the size, in bytes of JSON, above which Proto logs a
+// truncated string instead of the message.
+const protoMaxSize = 16 << 10
+
+// Proto returns a field holding m encoded as JSON by protojson, with the field
+// names of the .proto file, rather than the noisy output of %v. A nil message
+// is logged as null. Messages larger than 16 KiB of JSON are logged as a
+// string cut at that size and ending with "…(truncated)". m is encoded only
+// when the entry is written.
+func Proto(key string, m proto.Message) zap.Field {
+	return zap.Reflect(key, protoValue{m})
+}
+
+// protoValue encodes its message with protojson.
+type protoValue struct {
+	m proto.Message
+}
+
+func (p protoValue) MarshalJSON() ([]byte, error) {
+	if p.m == nil || !p.m.ProtoReflect().IsValid() {
+		return []byte("null"), nil
+	}
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(p.m)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomly adds spaces to keep its output from being relied on
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, b); err != nil {
+		return nil, err
+	}
+	b = compact.Bytes()
+	if len(b) <= protoMaxSize {
+		return b, nil
+	}
+	cut := protoMaxSize
+	for cut > 0 && !utf8.RuneStart(b[cut]) {
+		cut--
+	}
+	return json.Marshal(string(b[:cut]) + truncatedMarker)
+}
@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RateLimit configures the rate limiting of identical messages: each distinct
+// message gets its own token bucket, so a flood of one message is capped while
+// other messages keep flowing. Suppressed entries are counted and reported in
+// a periodic summary. It can be combined with sampling.
+type RateLimit struct {
+	// PerSecond is the sustained number of entries per second allowed for
+	// each message.
+	PerSecond float64
+	// Burst is the number of entries allowed for each message before the rate
+	// applies. Defaults to 1.
+	Burst int
+	// SummaryInterval is how often suppressed messages are reported, in a
+	// Warn entry per message with the "suppressed_message" and
+	// "suppressed_count" fields. Defaults to one minute.
+	SummaryInterval time.Duration
+}
+
+// rateLimitCore is a zapcore.Core dropping the entries whose message exceeds
+// its rate.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *messageLimiter
+}
+
+// newRateLimitCore wraps core with the rate limiting configured by rl, and
+// starts reporting the suppressed messages every SummaryInterval until the
+// returned function is called.
+func newRateLimitCore(core zapcore.Core, rl RateLimit) (zapcore.Core, func()) {
+	burst := float64(rl.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	interval := rl.SummaryInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	limiter := &messageLimiter{
+		base:    core,
+		rate:    rl.PerSecond,
+		burst:   burst,
+		buckets: make(map[string]*messageBucket),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go limiter.summarizeEvery(interval)
+	return &rateLimitCore{Core: core, limiter: limiter}, limiter.close
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.limiter.allow(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// messageLimiter holds the token buckets of the messages.
+type messageLimiter struct {
+	base  zapcore.Core
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*messageBucket
+
+	// stop stops the summary goroutine, which closes done when it returns.
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// messageBucket is the token bucket of a message.
+type messageBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// allow reports whether ent may be logged.
+func (m *messageLimiter) allow(ent zapcore.Entry) bool {
+	m.mu.Lock()
+	b, ok := m.buckets[ent.Message]
+	if !ok {
+		b = &messageBucket{tokens: m.burst, last: ent.Time}
+		m.buckets[ent.Message] = b
+	}
+	b.tokens += ent.Time.Sub(b.last).Seconds() * m.rate
+	if b.tokens > m.burst {
+		b.tokens = m.burst
+	}
+	b.last = ent.Time
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	} else {
+		b.suppressed++
+	}
+	m.mu.Unlock()
+	return allowed
+}
+
+// summarizeEvery reports the suppressed messages every interval, even when
+// nothing is logged anymore, until m is closed.
+func (m *messageLimiter) summarizeEvery(interval time.Duration) {
+	defer close(m.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			m.summarize(now)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// close stops the periodic summary and reports the messages suppressed since
+// the last one.
+func (m *messageLimiter) close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+	m.summarize(time.Now())
+}
+
+// summarize reports the messages suppressed since the last summary.
+func (m *messageLimiter) summarize(now time.Time) {
+	m.mu.Lock()
+	summary := m.collect(now)
+	m.mu.Unlock()
+	for msg, n := range summary {
+		m.report(now, msg, n)
+	}
+}
+
+// collect returns the suppressed counts to report and resets them. Buckets
+// which are full again are dropped, so the map does not grow with every
+// message ever logged. The caller must hold m.mu.
+func (m *messageLimiter) collect(now time.Time) map[string]int {
+	summary := make(map[string]int)
+	for msg, b := range m.buckets {
+		if b.suppressed > 0 {
+			summary[msg] = b.suppressed
+			b.suppressed = 0
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*m.rate >= m.burst {
+			delete(m.buckets, msg)
+		}
+	}
+	return summary
+}
+
+// report logs that n entries with message msg were suppressed.
+func (m *messageLimiter) report(now time.Time, msg string, n int) {
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: now, Message: "Suppressed repeated log messages"}
+	if ce := m.base.Check(ent, nil); ce != nil {
+		ce.Write(zap.String("suppressed_message", msg), zap.Int("suppressed_count", n))
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSummary(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	sink := newMemorySink(t, "ratelimit")
+	err := InitWithOptionsE(context.Background(), Options{
+		Quiet:       true,
+		OutputPaths: []string{"memory://ratelimit"},
+		RateLimit:   &RateLimit{PerSecond: 0.001, SummaryInterval: 10 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the flood stops and nothing else is logged
+	for i := 0; i < 5; i++ {
+		Logger().Info("Flood")
+	}
+	want := `"suppressed_message":"Flood","suppressed_count":4`
+	for deadline := time.Now().Add(5 * time.Second); !strings.Contains(sink.String(), want); {
+		if time.Now().After(deadline) {
+			t.Fatalf("output = %q, want the summary", sink.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRateLimitSummaryOnReset(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	sink := newMemorySink(t, "ratelimit-reset")
+	err := InitWithOptionsE(context.Background(), Options{
+		Quiet:       true,
+		OutputPaths: []string{"memory://ratelimit-reset"},
+		RateLimit:   &RateLimit{PerSecond: 0.001, SummaryInterval: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		Logger().Info("Flood")
+	}
+	Reset()
+	if !strings.Contains(sink.String(), `"suppressed_message":"Flood","suppressed_count":2`) {
+		t.Errorf("output = %q, want the pending summary", sink.String())
+	}
+}
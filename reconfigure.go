@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// globalCore holds the current core of the global logger, which Reconfigure
+// replaces.
+var globalCore *atomic.Pointer[zapcore.Core]
+
+// globalErrorOutput holds the current error output of the global logger,
+// which Reconfigure replaces.
+var globalErrorOutput *swapWriteSyncer
+
+// globalClose closes the outputs of the current core of the global logger.
+var globalClose func()
+
+// Reconfigure rebuilds the core of the global logger from opts and swaps it in
+// atomically, e.g. to reload the level and outputs from a configuration file
+// on SIGHUP without restarting. The global logger and the loggers derived from
+// it, including with With, keep working and switch to the new core. The old
+// core is then flushed and its outputs closed without waiting for the entries
+// being written concurrently, which may be lost or reported as write errors.
+// The level endpoint and SetLevel keep acting on the level, and the level
+// restored by SIGUSR2 becomes the one of opts.
+//
+// Only the core is rebuilt: the level, encoding, outputs, error outputs,
+// sampling, fields and the other outputs such as syslog, along with the format
+// of the trace fields and the audit logger. Settings of the logger itself,
+// e.g. development mode, the stacktrace level, the correlation ID keys and the
+// log level endpoint, stay as they were at Init. On error, the current core
+// and level are kept.
+func Reconfigure(opts Options) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if logger == nil {
+		return fmt.Errorf("reconfigure: %w", ErrNotInitialized)
+	}
+	g, err := buildGlobal(opts, atomicLevel)
+	if err != nil {
+		return err
+	}
+	newCore, newAuditCore := g.logger.Core(), g.audit.Core()
+	old, oldAudit, closeOld := globalCore.Swap(&newCore), auditCore.Swap(&newAuditCore), globalClose
+	globalErrorOutput.current.Store(&g.errorOutput)
+	globalClose = g.close
+	_ = (*old).Sync()
+	_ = (*oldAudit).Sync()
+	closeOld()
+	globalTraceFormat.Store(opts.traceFormat())
+	initConfig = g.config
+	initEnvironment = opts.Environment
+	initLevel = atomicLevel.Level()
+	return nil
+}
+
+// swapCore is a zapcore.Core delegating to the core held by current, so that
+// it can be replaced under the loggers using it. The fields added with With
+// are encoded right away, as zap does, and kept aside to be added again to the
+// current core when it changes.
+type swapCore struct {
+	current *atomic.Pointer[zapcore.Core]
+	fields  []zapcore.Field
+	cache   atomic.Pointer[swapCoreCache]
+}
+
+// swapCoreCache is the current core of a swapCore with its fields added.
+type swapCoreCache struct {
+	base *zapcore.Core
+	core zapcore.Core
+}
+
+// core returns the current core with the fields of c added.
+func (c *swapCore) core() zapcore.Core {
+	return c.coreFor(c.current.Load())
+}
+
+// coreFor returns base with the fields of c added.
+func (c *swapCore) coreFor(base *zapcore.Core) zapcore.Core {
+	if len(c.fields) == 0 {
+		return *base
+	}
+	if cached := c.cache.Load(); cached != nil && cached.base == base {
+		return cached.core
+	}
+	core := (*base).With(c.fields)
+	c.cache.Store(&swapCoreCache{base: base, core: core})
+	return core
+}
+
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	return c.core().Enabled(level)
+}
+
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	base := c.current.Load()
+	child := &swapCore{current: c.current, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+	child.cache.Store(&swapCoreCache{base: base, core: c.coreFor(base).With(fields)})
+	return child
+}
+
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.core().Check(ent, ce)
+}
+
+func (c *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.core().Write(ent, fields)
+}
+
+func (c *swapCore) Sync() error {
+	return c.core().Sync()
+}
+
+// swapWriteSyncer is a zapcore.WriteSyncer delegating to the one held by
+// current, so that the error output of the loggers using it can be replaced.
+type swapWriteSyncer struct {
+	current atomic.Pointer[zapcore.WriteSyncer]
+}
+
+func (ws *swapWriteSyncer) Write(p []byte) (int, error) {
+	return (*ws.current.Load()).Write(p)
+}
+
+func (ws *swapWriteSyncer) Sync() error {
+	return (*ws.current.Load()).Sync()
+}
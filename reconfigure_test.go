@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReconfigure(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	before := newMemorySink(t, "reconfigure-before")
+	after := newMemorySink(t, "reconfigure-after")
+	if err := InitWithOptionsE(context.Background(), Options{Quiet: true, OutputPaths: []string{"memory://reconfigure-before"}}); err != nil {
+		t.Fatal(err)
+	}
+	level := zap.WarnLevel
+	if err := Reconfigure(Options{Level: &level, OutputPaths: []string{"memory://reconfigure-after"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !before.isClosed() {
+		t.Error("the output of the previous core is not closed")
+	}
+	if initLevel != zap.WarnLevel {
+		t.Errorf("initLevel = %s, want warn", initLevel)
+	}
+	Logger().Info("Dropped")
+	Logger().Warn("Reconfigured")
+	if got := after.String(); strings.Contains(got, "Dropped") || !strings.Contains(got, "Reconfigured") {
+		t.Errorf("output = %q, want the warning only", got)
+	}
+
+	Reset()
+	if !after.isClosed() {
+		t.Error("Reset did not close the output")
+	}
+}
+
+func TestGlobalWithSnapshotsFields(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	out := newMemorySink(t, "with-snapshot")
+	if err := InitWithOptionsE(context.Background(), Options{Quiet: true, OutputPaths: []string{"memory://with-snapshot"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]int{"n": 1}
+	l := Logger().With(zap.Any("m", m))
+	m["n"] = 2
+	l.Info("Snapshot")
+	if got := out.String(); !strings.Contains(got, `"m":{"n":1}`) {
+		t.Errorf("output = %q, want the map as it was when added with With", got)
+	}
+}
+
+func TestReconfigureErrorKeepsLevel(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	out := newMemorySink(t, "reconfigure-error")
+	level := zap.WarnLevel
+	if err := InitWithOptionsE(context.Background(), Options{Quiet: true, Level: &level, OutputPaths: []string{"memory://reconfigure-error"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reconfigure(Options{DevelopmentMode: true, HighSeverityOutputPaths: []string{"/nonexistent/x"}}); err == nil {
+		t.Fatal("Reconfigure succeeded with an output which cannot be opened")
+	}
+	if got := GetLevel(); got != zap.WarnLevel {
+		t.Errorf("level = %s after a failed Reconfigure, want warn", got)
+	}
+	Logger().Debug("Dropped")
+	if got := out.String(); strings.Contains(got, "Dropped") {
+		t.Errorf("output = %q, want the debug entry dropped", got)
+	}
+}
+
+func TestReconfigureErrorOutput(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	newMemorySink(t, "reconfigure-output").err = errors.New("disk full")
+	before := newMemorySink(t, "reconfigure-errors-before")
+	after := newMemorySink(t, "reconfigure-errors-after")
+	if err := InitWithOptionsE(context.Background(), Options{
+		Quiet:            true,
+		OutputPaths:      []string{"memory://reconfigure-output"},
+		ErrorOutputPaths: []string{"memory://reconfigure-errors-before"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reconfigure(Options{
+		OutputPaths:      []string{"memory://reconfigure-output"},
+		ErrorOutputPaths: []string{"memory://reconfigure-errors-after"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	Logger().Info("Lost")
+	AuditLogger().Info("Lost")
+	if got := after.String(); strings.Count(got, "write error: disk full") != 2 {
+		t.Errorf("error output = %q, want both write errors", got)
+	}
+	if !before.isClosed() {
+		t.Error("the previous error output is not closed")
+	}
+}
@@ -1,19 +1,154 @@
 package logger
 
-import "runtime/debug"
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
 
 // PanicLogger will pass the error which caused the go routine to panic and the
 // stack trace onto the current SugaredLogger as a Fatal message and add the
-// field "op" with value of "panic_logger". Use it to log panics as structured
-// logs. Remember that you must defer this call at the beginning of each
-// goroutine!
+// field "op" with value of "panic_logger". The Go type of the recovered value
+// is added in the "panic_type" field and, when it is an error, the error in the
+// "error" field, so that panics can be queried by type. Use it to log panics as
+// structured logs. Remember that you must defer this call at the beginning of
+// each goroutine!
 //
 // Example
 //
-// 	defer logger.PanicLogger()
+//	defer logger.PanicLogger()
 func PanicLogger() {
 	if r := recover(); r != nil {
-		log := SugaredLogger().With("op", "panic_logger")
+		log := panicLogger("panic_logger", r)
+		// Fatal exits the process, so flush what is still buffered first.
+		_ = Sync()
 		log.Fatalf("panic: %s stack: %s", r, string(debug.Stack()))
 	}
-}
\ No newline at end of file
+}
+
+// RecoverLogger is like PanicLogger, but logs the panic at Error level instead
+// of Fatal, so the process keeps running: use it where a single panic must not
+// bring the whole application down, e.g. in HTTP handlers. The field "op" is
+// set to "recover_logger", and the same fields describe the recovered value.
+// If rethrow is true, the recovered value is panicked
+// again after logging so that an outer recovery can decide what to do.
+// Remember that you must defer this call! Repeated panics are logged once per
+// Options.PanicDedupWindow, if set.
+//
+// Example
+//
+//	defer logger.RecoverLogger(false)
+func RecoverLogger(rethrow bool) {
+	if r := recover(); r != nil {
+		log := panicLogger("recover_logger", r)
+		if panicDedupe.allow(r) {
+			log.Errorf("panic: %s stack: %s", r, string(debug.Stack()))
+		}
+		if rethrow {
+			panic(r)
+		}
+	}
+}
+
+// RecoverToError recovers from a panic, logs it with its stack trace at Error
+// level with the field "op" set to "recover_to_error" and the fields of
+// PanicLogger describing the recovered value, and turns it into an
+// error assigned to *err. Defer it with a pointer to a named return value to
+// return the panic as an error. A recovered error value is wrapped, so it can
+// be inspected with errors.Is and errors.As. Repeated panics are logged once
+// per Options.PanicDedupWindow, if set, and always turned into errors.
+//
+// Example
+//
+//	func handle() (err error) {
+//		defer logger.RecoverToError(&err)
+//		...
+//	}
+func RecoverToError(err *error) {
+	if r := recover(); r != nil {
+		log := panicLogger("recover_to_error", r)
+		if panicDedupe.allow(r) {
+			log.Errorf("panic: %s stack: %s", r, string(debug.Stack()))
+		}
+		if err == nil {
+			return
+		}
+		if e, ok := r.(error); ok {
+			*err = fmt.Errorf("recovered panic: %w", e)
+		} else {
+			*err = fmt.Errorf("recovered panic: %v", r)
+		}
+	}
+}
+
+// panicLogger returns the global sugared logger with the op field and the
+// fields describing the recovered value r.
+func panicLogger(op string, r interface{}) *CSugaredLogger {
+	log := SugaredLogger().With("op", op, "panic_type", fmt.Sprintf("%T", r))
+	if e, ok := r.(error); ok {
+		log = log.Err(e)
+	}
+	return log
+}
+
+// panicDedupe suppresses the repeated panics of RecoverLogger and
+// RecoverToError. It is nil, suppressing nothing, unless
+// Options.PanicDedupWindow is set.
+var panicDedupe *panicDeduper
+
+// panicDeduper lets the first of identical panics through in each window, and
+// counts the others, which are reported in a summary when the window ends.
+type panicDeduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*panicWindow
+}
+
+// panicWindow is the dedup window of a panic message.
+type panicWindow struct {
+	end        time.Time
+	suppressed int
+}
+
+func newPanicDeduper(window time.Duration) *panicDeduper {
+	return &panicDeduper{window: window, seen: make(map[string]*panicWindow)}
+}
+
+// allow reports whether the panic with the recovered value r must be logged.
+// Panics are identical when their values have the same type and message.
+func (d *panicDeduper) allow(r interface{}) bool {
+	if d == nil {
+		return true
+	}
+	key := fmt.Sprintf("%T: %v", r, r)
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w, ok := d.seen[key]
+	if !ok || !now.Before(w.end) {
+		d.seen[key] = &panicWindow{end: now.Add(d.window)}
+		return true
+	}
+	w.suppressed++
+	if w.suppressed == 1 {
+		time.AfterFunc(w.end.Sub(now), func() { d.summarize(key, w) })
+	}
+	return false
+}
+
+// summarize logs how many times the panic of w was suppressed, at the end of
+// its window.
+func (d *panicDeduper) summarize(key string, w *panicWindow) {
+	d.mu.Lock()
+	suppressed := w.suppressed
+	if d.seen[key] == w {
+		delete(d.seen, key)
+	}
+	d.mu.Unlock()
+	if logger == nil {
+		return
+	}
+	SugaredLogger().Warnw("Suppressed repeated panics", "suppressed_panic", key, "suppressed_count", suppressed)
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the value of redacted fields.
+const redactedValue = "***"
+
+// redactKeys holds the set of field keys to redact.
+var redactKeys atomic.Pointer[map[string]struct{}]
+
+// SetRedactKeys registers the field keys whose string values are replaced with
+// "***" when logged, e.g. "password" or "token". It applies to every logger,
+// sugared or not, including fields added with With and fields of nested
+// objects. Values logged with zap.Any are replaced entirely, as their contents
+// cannot be inspected. Calling it again replaces the previous set of keys, and
+// calling it with no keys disables redaction.
+func SetRedactKeys(keys []string) {
+	if len(keys) == 0 {
+		redactKeys.Store(nil)
+		return
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	redactKeys.Store(&set)
+}
+
+// redacted reports whether key must be redacted.
+func redacted(key string) bool {
+	set := redactKeys.Load()
+	if set == nil {
+		return false
+	}
+	_, ok := (*set)[key]
+	return ok
+}
+
+// redactEncoder is a zapcore.Encoder which redacts fields before encoding
+// them, whether they are added with With or logged with an entry.
+type redactEncoder struct {
+	zapcore.Encoder
+}
+
+// newRedactEncoder wraps enc with redaction. Every encoder of this package is
+// wrapped, which costs little as long as no keys are registered.
+func newRedactEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return redactEncoder{enc}
+}
+
+func (e redactEncoder) Clone() zapcore.Encoder {
+	return redactEncoder{e.Encoder.Clone()}
+}
+
+// EncodeEntry redacts the fields of the entry, which the wrapped encoder adds
+// to a clone of its own rather than through e.
+func (e redactEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	return e.Encoder.EncodeEntry(ent, redactFields(fields))
+}
+
+func (e redactEncoder) AddString(key, value string) {
+	redactObjectEncoder{e.Encoder}.AddString(key, value)
+}
+
+func (e redactEncoder) AddByteString(key string, value []byte) {
+	redactObjectEncoder{e.Encoder}.AddByteString(key, value)
+}
+
+func (e redactEncoder) AddReflected(key string, value interface{}) error {
+	return redactObjectEncoder{e.Encoder}.AddReflected(key, value)
+}
+
+func (e redactEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	return redactObjectEncoder{e.Encoder}.AddObject(key, m)
+}
+
+// redactFields returns fields with the values of redacted keys replaced, and
+// nested objects wrapped so that their fields get redacted too. fields is
+// returned as is when there is nothing to redact.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	if redactKeys.Load() == nil {
+		return fields
+	}
+	var out []zapcore.Field
+	for i, f := range fields {
+		rf, changed := redactField(f)
+		if changed && out == nil {
+			out = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+		if out != nil {
+			out = append(out, rf)
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactField returns the redacted version of f and whether it differs from f.
+func redactField(f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.StringType, zapcore.ByteStringType, zapcore.StringerType, zapcore.ReflectType:
+		if redacted(f.Key) {
+			return zap.String(f.Key, redactedValue), true
+		}
+	case zapcore.ObjectMarshalerType:
+		if m, ok := f.Interface.(zapcore.ObjectMarshaler); ok {
+			return zap.Object(f.Key, redactObject{m}), true
+		}
+	case zapcore.InlineMarshalerType:
+		if m, ok := f.Interface.(zapcore.ObjectMarshaler); ok {
+			return zap.Inline(redactObject{m}), true
+		}
+	}
+	return f, false
+}
+
+// redactObject redacts the fields of a nested object.
+type redactObject struct {
+	zapcore.ObjectMarshaler
+}
+
+func (o redactObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(redactObjectEncoder{enc})
+}
+
+// redactObjectEncoder is a zapcore.ObjectEncoder which redacts the string
+// values of redacted keys.
+type redactObjectEncoder struct {
+	zapcore.ObjectEncoder
+}
+
+func (e redactObjectEncoder) AddString(key, value string) {
+	if redacted(key) {
+		value = redactedValue
+	}
+	e.ObjectEncoder.AddString(key, value)
+}
+
+func (e redactObjectEncoder) AddByteString(key string, value []byte) {
+	if redacted(key) {
+		e.ObjectEncoder.AddString(key, redactedValue)
+		return
+	}
+	e.ObjectEncoder.AddByteString(key, value)
+}
+
+func (e redactObjectEncoder) AddReflected(key string, value interface{}) error {
+	if redacted(key) {
+		e.ObjectEncoder.AddString(key, redactedValue)
+		return nil
+	}
+	return e.ObjectEncoder.AddReflected(key, value)
+}
+
+func (e redactObjectEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	return e.ObjectEncoder.AddObject(key, redactObject{m})
+}
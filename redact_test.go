@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type credentials struct {
+	User     string
+	Password string
+}
+
+func (c credentials) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("user", c.User)
+	enc.AddString("password", c.Password)
+	return nil
+}
+
+func TestRedactKeys(t *testing.T) {
+	SetRedactKeys([]string{"password", "token"})
+	defer SetRedactKeys(nil)
+
+	for _, encoding := range []string{"json", "console", "logfmt"} {
+		t.Run(encoding, func(t *testing.T) {
+			out := newMemorySink(t, "redact-"+encoding)
+			l, err := New(Options{Encoding: encoding, OutputPaths: []string{"memory://redact-" + encoding}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.With(zap.String("token", "with-secret")).Info("Login",
+				zap.String("password", "entry-secret"),
+				zap.Object("credentials", credentials{User: "alice", Password: "nested-secret"}),
+				zap.String("user", "alice"))
+			got := out.String()
+			if strings.Contains(got, "secret") {
+				t.Errorf("output holds a secret: %s", got)
+			}
+			if !strings.Contains(got, "alice") || !strings.Contains(got, "***") {
+				t.Errorf("output lacks the redacted or the other fields: %s", got)
+			}
+		})
+	}
+}
+
+func TestWriteErrorsReported(t *testing.T) {
+	for name, opts := range map[string]Options{
+		"plain":     {},
+		"goroutine": {IncludeGoroutineId: true},
+		"severity":  {SeverityNumber: true},
+		"package":   {IncludePackage: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := newMemorySink(t, "write-error")
+			out.err = errors.New("disk full")
+			errOut := newMemorySink(t, "write-error-output")
+			opts.OutputPaths = []string{"memory://write-error"}
+			opts.ErrorOutputPaths = []string{"memory://write-error-output"}
+			l, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Info("Lost")
+			if got := errOut.String(); !strings.Contains(got, "write error: disk full") {
+				t.Errorf("error output = %q, want the write error", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotatingFileScheme is the zap sink scheme for rotating files. It is not
+// "lumberjack", which other packages register.
+const rotatingFileScheme = "go-logger-lumberjack"
+
+// rotatingFileErr is the error registering the rotating file sink, reported
+// by the Options using a RotatingFile rather than by a panic on import.
+var rotatingFileErr error
+
+func init() {
+	rotatingFileErr = zap.RegisterSink(rotatingFileScheme, newRotatingFileSink)
+}
+
+// RotatingFile configures a log file which is rotated by lumberjack. See
+// lumberjack.Logger for details on the fields.
+type RotatingFile struct {
+	// Filename is the file to write logs to. Backups are kept in the same
+	// directory.
+	Filename string
+	// MaxSize is the size in megabytes at which the file is rotated. Defaults
+	// to 100.
+	MaxSize int
+	// MaxBackups is the maximum number of old files to keep. Zero keeps all of
+	// them, subject to MaxAge.
+	MaxBackups int
+	// MaxAge is the maximum number of days to keep old files. Zero disables
+	// age based removal.
+	MaxAge int
+	// Compress gzips the rotated files.
+	Compress bool
+}
+
+// URL returns the zap sink URL of the rotating file. It can be used directly
+// in Options.OutputPaths, e.g. to log only to the rotating file.
+func (f RotatingFile) URL() string {
+	q := url.Values{}
+	q.Set("maxsize", strconv.Itoa(f.MaxSize))
+	q.Set("maxbackups", strconv.Itoa(f.MaxBackups))
+	q.Set("maxage", strconv.Itoa(f.MaxAge))
+	q.Set("compress", strconv.FormatBool(f.Compress))
+	u := url.URL{Scheme: rotatingFileScheme, Path: f.Filename, RawQuery: q.Encode()}
+	if !filepath.IsAbs(f.Filename) {
+		// a relative path would otherwise be parsed back as a host
+		u.Path, u.Opaque = "", f.Filename
+	}
+	return u.String()
+}
+
+// rotatingFileSink adapts lumberjack.Logger to zap.Sink.
+type rotatingFileSink struct {
+	*lumberjack.Logger
+}
+
+// Sync is a no-op, lumberjack does not buffer writes.
+func (rotatingFileSink) Sync() error {
+	return nil
+}
+
+// newRotatingFileSink builds a rotating file sink from a URL built by
+// RotatingFile.URL.
+func newRotatingFileSink(u *url.URL) (zap.Sink, error) {
+	filename := u.Path
+	if filename == "" {
+		// relative paths end up in the opaque part of the URL
+		filename = u.Opaque
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("rotating file %q has no filename", u.String())
+	}
+	q := u.Query()
+	l := &lumberjack.Logger{Filename: filename}
+	var err error
+	if l.MaxSize, err = queryInt(q, "maxsize"); err != nil {
+		return nil, err
+	}
+	if l.MaxBackups, err = queryInt(q, "maxbackups"); err != nil {
+		return nil, err
+	}
+	if l.MaxAge, err = queryInt(q, "maxage"); err != nil {
+		return nil, err
+	}
+	if c := q.Get("compress"); c != "" {
+		if l.Compress, err = strconv.ParseBool(c); err != nil {
+			return nil, fmt.Errorf("invalid rotating file compress value %q", c)
+		}
+	}
+	return rotatingFileSink{l}, nil
+}
+
+// queryInt parses the non-negative integer query parameter key, which defaults
+// to 0 when absent.
+func queryInt(q url.Values, key string) (int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid rotating file %s value %q", key, v)
+	}
+	return i, nil
+}
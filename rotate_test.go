@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRotatingFile(t *testing.T) {
+	// the scheme other packages register is left to them
+	if err := zap.RegisterSink("lumberjack", func(*url.URL) (zap.Sink, error) { return nil, nil }); err != nil {
+		t.Errorf("registering the lumberjack scheme: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Options{OutputPaths: []string{RotatingFile{Filename: filename, MaxSize: 1}.URL()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("Rotated")
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"msg":"Rotated"`) {
+		t.Errorf("file = %q, want the entry", b)
+	}
+}
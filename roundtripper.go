@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLoggingRoundTripper returns an http.RoundTripper which logs the outbound
+// requests sent through next, or http.DefaultTransport if nil, with their
+// method, URL, status code and duration. It logs with the logger of the
+// request context (see FromContext) decorated with its correlation ID, which
+// is also forwarded in the header set with SetCorrelationIdHeader unless the
+// request already has it. Responses are logged at Info level, at Warn level
+// for 4xx and at Error level for 5xx and failed requests.
+//
+//	client := &http.Client{Transport: logger.NewLoggingRoundTripper(nil)}
+func NewLoggingRoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &loggingRoundTripper{next: next}
+}
+
+// loggingRoundTripper is the http.RoundTripper returned by
+// NewLoggingRoundTripper.
+type loggingRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (t *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	log := FromContext(ctx).WithContextCorrelationId(ctx).With(
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.Redacted()),
+	)
+	if correlationId, ok := CorrelationIdFromContext(ctx); ok && req.Header.Get(correlationIdHeader) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(correlationIdHeader, correlationId)
+	}
+	log.Debug("HTTP client request")
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := zap.Duration("duration", time.Since(start))
+	if err != nil {
+		log.Error("HTTP client request failed", duration, zap.Error(err))
+		return resp, err
+	}
+
+	level := zapcore.InfoLevel
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		level = zapcore.ErrorLevel
+	case resp.StatusCode >= http.StatusBadRequest:
+		level = zapcore.WarnLevel
+	}
+	if ce := log.Check(level, "HTTP client response"); ce != nil {
+		ce.Write(zap.Int("status", resp.StatusCode), duration)
+	}
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingBypassLevel is the level from which entries are never sampled, so
+// that warnings and errors cannot be dropped under load.
+const samplingBypassLevel = zapcore.WarnLevel
+
+// newSampler wraps core with the sampling of s, counting entries per tick,
+// applied to the entries below samplingBypassLevel only.
+func newSampler(core zapcore.Core, s *zap.SamplingConfig, tick time.Duration) zapcore.Core {
+	return samplingBypassCore{
+		Core:    core,
+		sampled: zapcore.NewSamplerWithOptions(core, tick, s.Initial, s.Thereafter),
+	}
+}
+
+// samplingBypassCore is a zapcore.Core which hands the entries below
+// samplingBypassLevel over to a sampler, and the other ones to the core
+// itself.
+type samplingBypassCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+func (c samplingBypassCore) With(fields []zapcore.Field) zapcore.Core {
+	return samplingBypassCore{Core: c.Core.With(fields), sampled: c.sampled.With(fields)}
+}
+
+func (c samplingBypassCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level >= samplingBypassLevel {
+		return c.Core.Check(ent, ce)
+	}
+	return c.sampled.Check(ent, ce)
+}
+
+// Sample returns an instance of the same logger which only logs every n-th
+// entry below Warn level, starting with the first one, e.g. for a noisy code
+// path. The count is shared by the loggers derived from the returned one and
+// comes on top of the sampling configured at Init. n below 2 returns l as is.
+func (l *CLogger) Sample(n int) *CLogger {
+	if n < 2 {
+		return l
+	}
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return everyNthCore{Core: c, n: uint64(n), count: new(atomic.Uint64)}
+	}))
+}
+
+// Sample returns an instance of the same logger which only logs every n-th
+// entry below Warn level, starting with the first one. n below 2 returns l as
+// is.
+func (l *CSugaredLogger) Sample(n int) *CSugaredLogger {
+	if n < 2 {
+		return l
+	}
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return everyNthCore{Core: c, n: uint64(n), count: new(atomic.Uint64)}
+	}))
+}
+
+// everyNthCore is a zapcore.Core which drops all the entries below
+// samplingBypassLevel but every n-th one.
+type everyNthCore struct {
+	zapcore.Core
+	n     uint64
+	count *atomic.Uint64
+}
+
+func (c everyNthCore) With(fields []zapcore.Field) zapcore.Core {
+	return everyNthCore{Core: c.Core.With(fields), n: c.n, count: c.count}
+}
+
+func (c everyNthCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < samplingBypassLevel && c.Enabled(ent.Level) && (c.count.Add(1)-1)%c.n != 0 {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// severityEncoder is a zapcore.Encoder which adds the syslog severity of the
+// level of an entry in the "severity_num" field, next to the level encoded by
+// the wrapped encoder.
+type severityEncoder struct {
+	zapcore.Encoder
+}
+
+func (e severityEncoder) Clone() zapcore.Encoder {
+	return severityEncoder{e.Encoder.Clone()}
+}
+
+func (e severityEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	return e.Encoder.EncodeEntry(ent, append(fields[:len(fields):len(fields)], zap.Int("severity_num", syslogSeverity(ent.Level))))
+}
+
+// syslogSeverity returns the syslog severity of level, as the syslog output
+// uses it: 7 (debug) for Debug, 6 (informational) for Info, 4 (warning) for
+// Warn, 3 (error) for Error and 2 (critical) above.
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSeverityNumber(t *testing.T) {
+	out := newMemorySink(t, "severity")
+	debug := zap.DebugLevel
+	l, err := New(Options{SeverityNumber: true, Level: &debug, OutputPaths: []string{"memory://severity"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("Debug")
+	l.Warn("Warn")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("output = %q, want 2 lines", out.String())
+	}
+	for i, want := range []string{`"severity_num":7`, `"severity_num":4`} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want %s", i, lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// InstallSignalHandlers makes the global logger switch to Debug level on
+// SIGUSR1 and back to the level it was initialized with on SIGUSR2, acting on
+// the same level as SetLevel and the log level endpoint. It is an alternative
+// to the log level endpoint where opening a port is not desirable. The handlers
+// are removed when the context passed to Init is done. You must have
+// initialized the logger prior to this call.
+//
+// On Windows, which has no such signals, InstallSignalHandlers does nothing.
+func InstallSignalHandlers() {
+	if logger == nil {
+		panic("logger not initialized. Call Init(ctx)")
+	}
+	l, ctx := logger, initCtx
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-c:
+				level := initLevel
+				if sig == syscall.SIGUSR1 {
+					level = zap.DebugLevel
+				}
+				atomicLevel.SetLevel(level)
+				l.Info("Logger level changed by signal", zap.Stringer("signal", sig), zap.Stringer("level", level))
+			}
+		}
+	}()
+}
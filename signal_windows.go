@@ -0,0 +1,9 @@
+package logger
+
+// InstallSignalHandlers does nothing on Windows, which has no SIGUSR1 and
+// SIGUSR2 signals.
+func InstallSignalHandlers() {
+	if logger == nil {
+		panic("logger not initialized. Call Init(ctx)")
+	}
+}
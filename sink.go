@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"fmt"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+// RegisterSink registers factory for the URL scheme, e.g. "kafka", so that
+// URLs such as "kafka://broker:9092/logs" can be used in OutputPaths,
+// ErrorOutputPaths, HighSeverityOutputPaths and AuditOutputPaths. It must be
+// called before Init, and fails if the scheme is invalid or already
+// registered, "file" included.
+func RegisterSink(scheme string, factory func(*url.URL) (zap.Sink, error)) error {
+	if err := zap.RegisterSink(scheme, factory); err != nil {
+		return fmt.Errorf("logger sink: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// memorySinks holds the sinks of the "memory://name" output paths of tests.
+var memorySinks sync.Map
+
+func init() {
+	if err := RegisterSink("memory", func(u *url.URL) (zap.Sink, error) {
+		s, _ := memorySinks.LoadOrStore(u.Host, &memorySink{})
+		sink := s.(*memorySink)
+		sink.mu.Lock()
+		sink.opened++
+		sink.mu.Unlock()
+		return sink, nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// memorySink is a zap.Sink keeping what is written to it, or failing writes
+// with err if set.
+type memorySink struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	err    error
+	opened int
+	closed bool
+}
+
+// newMemorySink returns a new sink for the "memory://name" output path.
+func newMemorySink(t *testing.T, name string) *memorySink {
+	t.Helper()
+	s := &memorySink{}
+	memorySinks.Store(name, s)
+	t.Cleanup(func() { memorySinks.Delete(name) })
+	return s
+}
+
+func (s *memorySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.closed {
+		return 0, errors.New("sink closed")
+	}
+	return s.buf.Write(p)
+}
+
+func (s *memorySink) Sync() error {
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// String returns what was written to the sink.
+func (s *memorySink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// isClosed reports whether the sink was closed.
+func (s *memorySink) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// openCount returns the number of times the sink was opened.
+func (s *memorySink) openCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opened
+}
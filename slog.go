@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler returns a log/slog Handler backed by the global logger, so that
+// slog.New(logger.SlogHandler()) logs through this package. You must have
+// initialized the logger prior to this call.
+func SlogHandler() slog.Handler {
+	return Logger().SlogHandler()
+}
+
+// SlogHandler returns a log/slog Handler backed by this logger. slog levels are
+// mapped to the closest zap level and attributes to zap fields, groups being
+// nested objects. The correlation ID found in the context passed to the slog
+// logger, if any, is added to every entry.
+func (l *CLogger) SlogHandler() slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// slogHandler adapts CLogger to slog.Handler. Attributes added outside of any
+// group go straight to the logger. Groups are kept aside with their attributes
+// and only rendered as nested objects when an entry is written, so that the
+// correlation ID always stays at the top level.
+type slogHandler struct {
+	l      *CLogger
+	groups []slogOpenGroup
+}
+
+// slogOpenGroup is a group opened with WithGroup and the fields added to it.
+type slogOpenGroup struct {
+	name   string
+	fields []zap.Field
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Core().Enabled(slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.l.Check(slogLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+	fields := make([]zap.Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if f, ok := slogField(a); ok {
+			fields = append(fields, f)
+		}
+		return true
+	})
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		groupFields := append(g.fields[:len(g.fields):len(g.fields)], fields...)
+		fields = fields[:0:0]
+		if len(groupFields) > 0 {
+			fields = append(fields, zap.Object(g.name, zapFields(groupFields)))
+		}
+	}
+	keys := h.l.keys()
+	if correlationId, ok := correlationIdString(contextCorrelationId(ctx, keys.contextKey)); ok {
+		fields = append(fields, zap.String(keys.fieldKey, correlationId))
+	}
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, a := range attrs {
+		if f, ok := slogField(a); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(h.groups) == 0 {
+		return &slogHandler{l: h.l.With(fields...)}
+	}
+	groups := append([]slogOpenGroup(nil), h.groups...)
+	last := &groups[len(groups)-1]
+	last.fields = append(last.fields[:len(last.fields):len(last.fields)], fields...)
+	return &slogHandler{l: h.l, groups: groups}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := append(h.groups[:len(h.groups):len(h.groups)], slogOpenGroup{name: name})
+	return &slogHandler{l: h.l, groups: groups}
+}
+
+// slogLevel maps a slog level to the closest zap level.
+func slogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// slogField converts a slog attribute to a zap field. It returns false for
+// attributes slog handlers must ignore.
+func slogField(a slog.Attr) (zap.Field, bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return zap.Skip(), false
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return zap.String(a.Key, a.Value.String()), true
+	case slog.KindInt64:
+		return zap.Int64(a.Key, a.Value.Int64()), true
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, a.Value.Uint64()), true
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, a.Value.Float64()), true
+	case slog.KindBool:
+		return zap.Bool(a.Key, a.Value.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(a.Key, a.Value.Duration()), true
+	case slog.KindTime:
+		return zap.Time(a.Key, a.Value.Time()), true
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return zap.Skip(), false
+		}
+		if a.Key == "" {
+			return zap.Inline(slogGroup(attrs)), true
+		}
+		return zap.Object(a.Key, slogGroup(attrs)), true
+	default:
+		return zap.Any(a.Key, a.Value.Any()), true
+	}
+}
+
+// slogGroup marshals the attributes of a slog group as a zap object.
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, a := range g {
+		if f, ok := slogField(a); ok {
+			f.AddTo(enc)
+		}
+	}
+	return nil
+}
+
+// zapFields marshals zap fields as a zap object.
+type zapFields []zap.Field
+
+func (fs zapFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range fs {
+		f.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// StdLogger returns a standard library *log.Logger which writes to the global
+// logger at the given level, e.g. for http.Server.ErrorLog. You must have
+// initialized the logger prior to this call.
+func StdLogger(level zapcore.Level) *log.Logger {
+	return Logger().StdLogger(level)
+}
+
+// StdLogger returns a standard library *log.Logger which writes to this logger
+// at the given level, keeping its fields such as the correlation ID. Unknown
+// levels fall back to Info.
+func (l *CLogger) StdLogger(level zapcore.Level) *log.Logger {
+	std, err := zap.NewStdLogAt(&l.Logger, level)
+	if err != nil {
+		return zap.NewStdLog(&l.Logger)
+	}
+	return std
+}
+
+// Writer returns an io.Writer which writes to the global logger at the given
+// level, for libraries which only take a writer for their output. You must
+// have initialized the logger prior to this call.
+func Writer(level zapcore.Level) io.Writer {
+	return Logger().Writer(level)
+}
+
+// Writer returns an io.Writer which logs every write to this logger as one
+// message at the given level, trailing newlines trimmed, keeping its fields
+// such as the correlation ID.
+func (l *CLogger) Writer(level zapcore.Level) io.Writer {
+	return &levelWriter{l: l.Logger.WithOptions(zap.AddCallerSkip(1)), level: level}
+}
+
+// levelWriter is the io.Writer returned by Writer.
+type levelWriter struct {
+	l     *zap.Logger
+	level zapcore.Level
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	if ce := w.l.Check(w.level, string(bytes.TrimRight(p, "\r\n"))); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Syslog configures the delivery of logs to a syslog daemon, in addition to
+// the output paths. Entries are encoded like the other outputs, and their
+// syslog severity is derived from their level.
+type Syslog struct {
+	// Network and Address of the syslog daemon, e.g. "udp" and
+	// "logs.example.com:514". Both empty connects to the local daemon.
+	Network string
+	Address string
+	// Facility is the syslog facility, e.g. int(syslog.LOG_LOCAL0). Defaults
+	// to LOG_USER.
+	Facility int
+	// Tag is the syslog tag. Defaults to the program name.
+	Tag string
+}
+
+// newSyslogTeeCore returns the syslog core to tee with the core built from cfg,
+// using the same encoder, level and sampling, and the function closing the
+// connection to the daemon.
+func newSyslogTeeCore(s Syslog, cfg zap.Config, opts Options) (zapcore.Core, func(), error) {
+	enc, err := newEncoder(cfg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	core, closeConn, err := newSyslogCore(s, enc, cfg.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+	return teeCore(core, cfg), closeConn, nil
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogCore fails, as log/syslog is not available on this platform.
+func newSyslogCore(Syslog, zapcore.Encoder, zapcore.LevelEnabler) (zapcore.Core, func(), error) {
+	return nil, nil, errors.New("syslog is not supported on this platform")
+}
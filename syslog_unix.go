@@ -0,0 +1,73 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"log/syslog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogCore returns a core writing entries encoded with enc to the syslog
+// daemon configured by s, and the function closing the connection. The
+// underlying syslog.Writer reconnects by itself when the connection drops.
+func newSyslogCore(s Syslog, enc zapcore.Encoder, enab zapcore.LevelEnabler) (zapcore.Core, func(), error) {
+	facility := syslog.Priority(s.Facility)
+	if facility == 0 {
+		facility = syslog.LOG_USER
+	}
+	w, err := syslog.Dial(s.Network, s.Address, facility|syslog.LOG_INFO, s.Tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &syslogCore{LevelEnabler: enab, enc: enc, w: w}, func() { _ = w.Close() }, nil
+}
+
+// syslogCore is a zapcore.Core writing to a syslog.Writer with the severity
+// matching the level of the entries.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   *syslog.Writer
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &syslogCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), w: c.w}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+	switch ent.Level {
+	case zapcore.DebugLevel:
+		return c.w.Debug(msg)
+	case zapcore.InfoLevel:
+		return c.w.Info(msg)
+	case zapcore.WarnLevel:
+		return c.w.Warning(msg)
+	case zapcore.ErrorLevel:
+		return c.w.Err(msg)
+	default:
+		return c.w.Crit(msg)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
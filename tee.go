@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// teeCore prepares a core to be teed with the core built from cfg, adding the
+// initial fields of cfg which zap only applies to its own core.
+func teeCore(core zapcore.Core, cfg zap.Config) zapcore.Core {
+	if len(cfg.InitialFields) > 0 {
+		keys := make([]string, 0, len(cfg.InitialFields))
+		for k := range cfg.InitialFields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]zap.Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, zap.Any(k, cfg.InitialFields[k]))
+		}
+		core = core.With(fields)
+	}
+	return core
+}
+
+// newHighSeverityTeeCore returns a core writing the entries at or above level,
+// and enabled by the level of cfg, to paths with the encoder of cfg and opts,
+// and the function closing paths.
+func newHighSeverityTeeCore(paths []string, level zapcore.Level, cfg zap.Config, opts Options) (zapcore.Core, func(), error) {
+	enc, err := newEncoder(cfg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws, closePaths, err := zap.Open(paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+	enab := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= level && cfg.Level.Enabled(l)
+	})
+	return teeCore(zapcore.NewCore(enc, ws, enab), cfg), closePaths, nil
+}
@@ -0,0 +1,9 @@
+package logger
+
+import "go.uber.org/zap"
+
+// NewNop returns a logger which discards everything. It does not touch the
+// global logger, so it can be injected in unit tests without calling Init.
+func NewNop() *CLogger {
+	return &CLogger{Logger: *zap.NewNop()}
+}
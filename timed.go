@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Timed starts timing op and returns a function which logs it at Debug level
+// with its duration, in milliseconds, in the "duration_ms" field. Defer it:
+//
+//	defer logger.Logger().WithContextCorrelationId(ctx).Timed("load_orders")()
+//
+// The entry carries the fields of the logger, e.g. its correlation ID.
+func (l *CLogger) Timed(op string) func() {
+	start := time.Now()
+	return func() {
+		l.Logger.WithOptions(zap.AddCallerSkip(1)).Debug("Operation timed", zap.String("op", op), durationMs(start))
+	}
+}
+
+// Timed starts timing op and returns a function which logs it at Debug level
+// with its duration, in milliseconds, in the "duration_ms" field. Defer it.
+func (l *CSugaredLogger) Timed(op string) func() {
+	start := time.Now()
+	return func() {
+		l.SugaredLogger.WithOptions(zap.AddCallerSkip(1)).Debugw("Operation timed", zap.String("op", op), durationMs(start))
+	}
+}
+
+// durationMs returns the time elapsed since start as the "duration_ms" field.
+func durationMs(start time.Time) zap.Field {
+	return zap.Float64("duration_ms", float64(time.Since(start))/float64(time.Millisecond))
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// traceFormat holds the keys and formats of the trace fields.
+type traceFormat struct {
+	traceIdKey    string
+	spanIdKey     string
+	formatTraceId func(trace.TraceID) string
+	formatSpanId  func(trace.SpanID) string
+}
+
+// globalTraceFormat holds the trace format of the global logger, set by Init
+// and Reconfigure. Loggers derived from the global one follow it.
+var globalTraceFormat atomic.Pointer[traceFormat]
+
+func init() {
+	globalTraceFormat.Store(Options{}.traceFormat())
+}
+
+// traceFormat returns the keys and formats of the trace fields for the modes
+// enabled in o.
+func (o Options) traceFormat() *traceFormat {
+	f := &traceFormat{
+		traceIdKey:    "trace_id",
+		spanIdKey:     "span_id",
+		formatTraceId: func(id trace.TraceID) string { return id.String() },
+		formatSpanId:  func(id trace.SpanID) string { return id.String() },
+	}
+	switch {
+	case o.DatadogMode:
+		// Datadog wants the lower 64 bits of the IDs as decimal numbers
+		f.traceIdKey, f.spanIdKey = "dd.trace_id", "dd.span_id"
+		f.formatTraceId = func(id trace.TraceID) string {
+			return strconv.FormatUint(binary.BigEndian.Uint64(id[8:]), 10)
+		}
+		f.formatSpanId = func(id trace.SpanID) string {
+			return strconv.FormatUint(binary.BigEndian.Uint64(id[:]), 10)
+		}
+	case o.GCPMode:
+		f.traceIdKey, f.spanIdKey = gcpTraceKey, gcpSpanIdKey
+		if o.GCPProjectID != "" {
+			prefix := "projects/" + o.GCPProjectID + "/traces/"
+			f.formatTraceId = func(id trace.TraceID) string { return prefix + id.String() }
+		}
+	}
+	return f
+}
+
+// WithContextTrace returns an instance of the same logger with the "trace_id"
+// and "span_id" fields of the OpenTelemetry span found in the context added to
+// it (see DatadogMode and GCPMode for their names in these modes). The logger
+// is returned unchanged if the context carries no valid span.
+func (l *CLogger) WithContextTrace(ctx context.Context) *CLogger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
+	}
+	return l.With(traceFields(l.traceFormat, sc)...)
+}
+
+// WithContextTrace returns an instance of the same logger with the "trace_id"
+// and "span_id" fields of the OpenTelemetry span found in the context added to
+// it (see DatadogMode and GCPMode for their names in these modes). The logger
+// is returned unchanged if the context carries no valid span.
+func (l *CSugaredLogger) WithContextTrace(ctx context.Context) *CSugaredLogger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
+	}
+	return &CSugaredLogger{SugaredLogger: *l.SugaredLogger.Desugar().With(traceFields(l.traceFormat, sc)...).Sugar(), correlationKeys: l.keys(), traceFormat: l.traceFormat}
+}
+
+// WithTraceparent returns an instance of the same logger with the trace and
+// span ID fields, as added by WithContextTrace, of a W3C traceparent header,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", for services
+// which receive the header without running an OpenTelemetry SDK. An invalid
+// header is logged at Debug level, and the logger returned unchanged.
+func (l *CLogger) WithTraceparent(header string) *CLogger {
+	sc, err := parseTraceparent(header)
+	if err != nil {
+		l.Logger.WithOptions(zap.AddCallerSkip(1)).Debug("Logger ignored an invalid traceparent header", zap.String("traceparent", header), zap.Error(err))
+		return l
+	}
+	return l.With(traceFields(l.traceFormat, sc)...)
+}
+
+// parseTraceparent returns the span context of a W3C traceparent header,
+// made of the version, trace ID, parent span ID and flags. Versions above 00
+// may add fields after the flags.
+func parseTraceparent(header string) (trace.SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || parts[0] == "00" && len(parts) != 4 {
+		return trace.SpanContext{}, errors.New("traceparent must have 4 fields")
+	}
+	if len(parts[0]) != 2 || parts[0] == "ff" {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent version %q", parts[0])
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent version %q", parts[0])
+	}
+	// the ID parsers accept upper case, which the header forbids
+	if len(parts[1]) != 32 || strings.ToLower(parts[1]) != parts[1] {
+		return trace.SpanContext{}, fmt.Errorf("invalid trace ID %q", parts[1])
+	}
+	traceId, err := trace.TraceIDFromHex(parts[1])
+	if err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid trace ID %q: %w", parts[1], err)
+	}
+	if len(parts[2]) != 16 || strings.ToLower(parts[2]) != parts[2] {
+		return trace.SpanContext{}, fmt.Errorf("invalid span ID %q", parts[2])
+	}
+	spanId, err := trace.SpanIDFromHex(parts[2])
+	if err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid span ID %q: %w", parts[2], err)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent flags %q", parts[3])
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.TraceFlags(flags[0]),
+		Remote:     true,
+	}), nil
+}
+
+// traceFields returns the trace and span ID fields of sc in format f, or in the
+// format of the global logger if f is nil.
+func traceFields(f *traceFormat, sc trace.SpanContext) []zap.Field {
+	if f == nil {
+		f = globalTraceFormat.Load()
+	}
+	return []zap.Field{
+		zap.String(f.traceIdKey, f.formatTraceId(sc.TraceID())),
+		zap.String(f.spanIdKey, f.formatSpanId(sc.SpanID())),
+	}
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithContextTraceFormat(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 42},
+		SpanID:  trace.SpanID{0, 0, 0, 0, 0, 0, 0, 7},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	for name, tt := range map[string]struct {
+		opts Options
+		want []string
+	}{
+		"default": {Options{}, []string{`"trace_id":"0000000000000000000000000000002a"`, `"span_id":"0000000000000007"`}},
+		"datadog": {Options{DatadogMode: true}, []string{`"dd.trace_id":"42"`, `"dd.span_id":"7"`}},
+		"gcp": {Options{GCPMode: true, GCPProjectID: "acme"}, []string{
+			`"logging.googleapis.com/trace":"projects/acme/traces/0000000000000000000000000000002a"`,
+			`"logging.googleapis.com/spanId":"0000000000000007"`,
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := newMemorySink(t, "trace-"+name)
+			tt.opts.OutputPaths = []string{"memory://trace-" + name}
+			l, err := New(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.WithContextTrace(ctx).Info("Traced")
+			l.Sugar().WithContextTrace(ctx).Info("Traced")
+			got := out.String()
+			for _, want := range tt.want {
+				if strings.Count(got, want) != 2 {
+					t.Errorf("output = %q, want %s twice", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// truncatedMarker ends the strings, arrays and objects cut by TruncatedAny.
+const truncatedMarker = "…(truncated)"
+
+// TruncatedAny returns a field holding v, like zap.Any, with its strings cut
+// after maxLen characters and its arrays and objects after maxLen elements, at
+// any depth, so that a huge value does not blow up the size of the logs. Cut
+// values end with "…(truncated)": as a suffix of strings, as the last element
+// of arrays and as an extra key of objects, holding the number of keys left
+// out. A maxLen below 1 disables truncation.
+//
+// v is encoded as JSON, honoring struct tags and json.Marshaler, and only when
+// the entry is written.
+func TruncatedAny(key string, v interface{}, maxLen int) zap.Field {
+	return zap.Reflect(key, truncatedValue{v: v, maxLen: maxLen})
+}
+
+// truncatedValue encodes its value truncated to maxLen.
+type truncatedValue struct {
+	v      interface{}
+	maxLen int
+}
+
+func (t truncatedValue) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(t.v)
+	if err != nil || t.maxLen < 1 {
+		return b, err
+	}
+	// decoding the JSON value gives back plain strings, arrays and objects,
+	// whatever the type of v
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(truncate(decoded, t.maxLen))
+}
+
+// truncate cuts the strings, arrays and objects of v, a decoded JSON value, to
+// maxLen.
+func truncate(v interface{}, maxLen int) interface{} {
+	switch v := v.(type) {
+	case string:
+		if utf8.RuneCountInString(v) <= maxLen {
+			return v
+		}
+		n := 0
+		for i := range v {
+			if n == maxLen {
+				return v[:i] + truncatedMarker
+			}
+			n++
+		}
+		return v
+	case []interface{}:
+		cut := len(v) > maxLen
+		if cut {
+			v = v[:maxLen]
+		}
+		for i := range v {
+			v[i] = truncate(v[i], maxLen)
+		}
+		if cut {
+			v = append(v, truncatedMarker)
+		}
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make(map[string]interface{}, len(v))
+		for i, k := range keys {
+			if i == maxLen {
+				out[truncatedMarker] = len(keys) - maxLen
+				break
+			}
+			out[k] = truncate(v[k], maxLen)
+		}
+		return out
+	default:
+		return v
+	}
+}